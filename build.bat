@@ -1,4 +1,4 @@
 mkdir bin
 rsrc -ico quail-gui.ico -manifest quail-gui.exe.manifest
 copy /y quail-gui.exe.manifest bin\quail-gui.exe.manifest
-go build -buildmode=pie -ldflags="-s -w" -o quail-gui.exe main.go
\ No newline at end of file
+go build -buildmode=pie -ldflags="-s -w" -o quail-gui.exe .
\ No newline at end of file
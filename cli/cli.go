@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// SetOutput redirects command output, e.g. to a console attached after startup
+func SetOutput(out io.Writer, errOut io.Writer) {
+	stdout = out
+	stderr = errOut
+}
+
+// command is a headless operation invoked via quail-gui <name> <archive> [args]
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"list":    {usage: "list <archive>", run: runList},
+	"extract": {usage: "extract [-out dir] [-png] <archive> [entry...]", run: runExtract},
+	"add":     {usage: "add <archive> <file> [file...]", run: runAdd},
+	"remove":  {usage: "remove <archive> <entry> [entry...]", run: runRemove},
+	"rename":  {usage: "rename <archive> <entry> <new name>", run: runRename},
+}
+
+// IsCommand returns true if name is a headless command
+func IsCommand(name string) bool {
+	_, ok := commands[strings.ToLower(name)]
+	return ok
+}
+
+// Run executes a headless command and returns the process exit code
+func Run(args []string) int {
+	if len(args) == 0 {
+		Usage()
+		return 2
+	}
+
+	name := strings.ToLower(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
+		Usage()
+		return 2
+	}
+
+	err := cmd.run(args[1:])
+	if err != nil {
+		if _, ok := err.(*usageError); ok {
+			fmt.Fprintf(stderr, "%s: %s\nusage: quail-gui %s\n", name, err, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "%s: %s\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// Usage prints a list of headless commands
+func Usage() {
+	fmt.Fprintf(stderr, "usage: quail-gui [archive|file]\n")
+	for _, name := range []string{"list", "extract", "add", "remove", "rename"} {
+		fmt.Fprintf(stderr, "       quail-gui %s\n", commands[name].usage)
+	}
+}
+
+// usageError is returned when a command is invoked with bad arguments
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, a ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
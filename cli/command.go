@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-gui/session"
+)
+
+func runList(args []string) error {
+	if len(args) != 1 {
+		return usagef("expected 1 archive, got %d arguments", len(args))
+	}
+
+	sess, err := session.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open %s: %w", args[0], err)
+	}
+	defer sess.Close()
+
+	for _, fe := range sess.Files() {
+		fmt.Fprintf(stdout, "%s\t%d\n", fe.Name(), len(fe.Data()))
+	}
+	return nil
+}
+
+func runExtract(args []string) error {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	outDir := fs.String("out", ".", "directory to extract to")
+	isPNG := fs.Bool("png", false, "decode dds, bmp and png textures to png")
+	err := fs.Parse(args)
+	if err != nil {
+		return usagef("%s", err)
+	}
+	args = fs.Args()
+	if len(args) < 1 {
+		return usagef("archive is required")
+	}
+
+	sess, err := session.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open %s: %w", args[0], err)
+	}
+	defer sess.Close()
+
+	paths, err := sess.Extract(*outDir, args[1:], *isPNG, nil)
+	for _, path := range paths {
+		fmt.Fprintf(stdout, "%s\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func runAdd(args []string) error {
+	if len(args) < 2 {
+		return usagef("archive and at least one file are required")
+	}
+
+	sess, err := session.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open %s: %w", args[0], err)
+	}
+	defer sess.Close()
+
+	for _, path := range args[1:] {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		name := strings.ToLower(filepath.Base(path))
+		if sess.Has(name) {
+			err = sess.Replace(name, data)
+		} else {
+			err = sess.Add(name, data)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "added %s\n", name)
+	}
+
+	return sess.Save()
+}
+
+func runRemove(args []string) error {
+	if len(args) < 2 {
+		return usagef("archive and at least one entry are required")
+	}
+
+	sess, err := session.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open %s: %w", args[0], err)
+	}
+	defer sess.Close()
+
+	for _, name := range args[1:] {
+		err = sess.Delete(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "removed %s\n", name)
+	}
+
+	return sess.Save()
+}
+
+func runRename(args []string) error {
+	if len(args) != 3 {
+		return usagef("expected archive, entry and new name, got %d arguments", len(args))
+	}
+
+	sess, err := session.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open %s: %w", args[0], err)
+	}
+	defer sess.Close()
+
+	err = sess.Rename(args[1], args[2])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "renamed %s to %s\n", args[1], args[2])
+
+	return sess.Save()
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -17,13 +18,19 @@ var (
 type Config struct {
 	baseName     string
 	IsVirtualWld bool
+	BackupCount  int      // number of timestamped .bak copies kept per archive
+	RecentCount  int      // number of archives kept in RecentFiles
+	RecentFiles  []string // recently opened archives, newest first
+	PinnedFiles  []string // favorite archives always shown in Open Recent
 }
 
 // New creates a new configuration
 func New(ctx context.Context, baseName string) (*Config, error) {
 	var f *os.File
 	cfg := &Config{
-		baseName: baseName,
+		baseName:    baseName,
+		BackupCount: 3,
+		RecentCount: 10,
 	}
 	instance = cfg
 	path := baseName + ".ini"
@@ -57,9 +64,7 @@ func New(ctx context.Context, baseName string) (*Config, error) {
 	}
 
 	if isNewConfig {
-		cfg = &Config{
-			IsVirtualWld: true,
-		}
+		cfg.IsVirtualWld = true
 		err = cfg.Save()
 		if err != nil {
 			return nil, fmt.Errorf("save config: %w", err)
@@ -86,7 +91,14 @@ func ByKey(key string) (string, error) {
 	switch key {
 	case "is_virtual_wld":
 		return fmt.Sprintf("%v", instance.IsVirtualWld), nil
-
+	case "backup_count":
+		return fmt.Sprintf("%d", instance.BackupCount), nil
+	case "recent_count":
+		return fmt.Sprintf("%d", instance.RecentCount), nil
+	case "recent_files":
+		return joinPaths(instance.RecentFiles), nil
+	case "pinned_files":
+		return joinPaths(instance.PinnedFiles), nil
 	}
 	return "", fmt.Errorf("unknown key: %s", key)
 }
@@ -98,8 +110,26 @@ func SetByKey(key, value string) error {
 	switch key {
 	case "is_virtual_wld":
 		instance.IsVirtualWld = value == "true"
+	case "backup_count":
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("parse backup_count: %w", err)
+		}
+		instance.BackupCount = count
+	case "recent_count":
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("parse recent_count: %w", err)
+		}
+		instance.RecentCount = count
+	case "recent_files":
+		instance.RecentFiles = splitPaths(value)
+	case "pinned_files":
+		instance.PinnedFiles = splitPaths(value)
+	default:
+		return fmt.Errorf("unknown key: %s", key)
 	}
-	return fmt.Errorf("unknown key: %s", key)
+	return nil
 }
 
 // Verify returns an error if configuration appears off
@@ -118,7 +148,7 @@ func decode(r io.Reader, cfg *Config) error {
 		if !strings.Contains(line, "=") {
 			continue
 		}
-		parts := strings.Split(line, "=")
+		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
 			continue
 		}
@@ -129,6 +159,21 @@ func decode(r io.Reader, cfg *Config) error {
 			if strings.ToLower(value) == "true" {
 				cfg.IsVirtualWld = true
 			}
+		case "backup_count":
+			// a bad count keeps the default rather than failing the whole load
+			count, err := strconv.Atoi(value)
+			if err == nil && count >= 0 {
+				cfg.BackupCount = count
+			}
+		case "recent_count":
+			count, err := strconv.Atoi(value)
+			if err == nil && count >= 0 {
+				cfg.RecentCount = count
+			}
+		case "recent_files":
+			cfg.RecentFiles = splitPaths(value)
+		case "pinned_files":
+			cfg.PinnedFiles = splitPaths(value)
 		}
 	}
 	return nil
@@ -159,6 +204,10 @@ func (c *Config) Save() error {
 	defer r.Close()
 
 	tmpConfig := &Config{}
+	isBackupCountSaved := false
+	isRecentCountSaved := false
+	isRecentFilesSaved := false
+	isPinnedFilesSaved := false
 
 	out := ""
 	reader := bufio.NewScanner(r)
@@ -172,7 +221,7 @@ func (c *Config) Save() error {
 			out += line + "\n"
 			continue
 		}
-		parts := strings.Split(line, "=")
+		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
 			continue
 		}
@@ -191,6 +240,30 @@ func (c *Config) Save() error {
 				value = "false"
 			}
 			tmpConfig.IsVirtualWld = true
+		case "backup_count":
+			if isBackupCountSaved {
+				continue
+			}
+			value = fmt.Sprintf("%d", c.BackupCount)
+			isBackupCountSaved = true
+		case "recent_count":
+			if isRecentCountSaved {
+				continue
+			}
+			value = fmt.Sprintf("%d", c.RecentCount)
+			isRecentCountSaved = true
+		case "recent_files":
+			if isRecentFilesSaved {
+				continue
+			}
+			value = joinPaths(c.RecentFiles)
+			isRecentFilesSaved = true
+		case "pinned_files":
+			if isPinnedFilesSaved {
+				continue
+			}
+			value = joinPaths(c.PinnedFiles)
+			isPinnedFilesSaved = true
 		}
 		line = fmt.Sprintf("%s = %s", key, value)
 		out += line + "\n"
@@ -203,6 +276,18 @@ func (c *Config) Save() error {
 			out += "is_virtual_wld = false\n"
 		}
 	}
+	if !isBackupCountSaved {
+		out += fmt.Sprintf("backup_count = %d\n", c.BackupCount)
+	}
+	if !isRecentCountSaved {
+		out += fmt.Sprintf("recent_count = %d\n", c.RecentCount)
+	}
+	if !isRecentFilesSaved {
+		out += fmt.Sprintf("recent_files = %s\n", joinPaths(c.RecentFiles))
+	}
+	if !isPinnedFilesSaved {
+		out += fmt.Sprintf("pinned_files = %s\n", joinPaths(c.PinnedFiles))
+	}
 
 	err = os.WriteFile(c.baseName+".ini", []byte(out), 0644)
 	if err != nil {
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// pathSeparator joins path lists in the ini, it is not valid in windows paths
+const pathSeparator = "|"
+
+// AddRecent moves path to the front of RecentFiles, trimming the list to RecentCount
+func (c *Config) AddRecent(path string) {
+	recent := []string{path}
+	for _, recentPath := range c.RecentFiles {
+		if strings.EqualFold(recentPath, path) {
+			continue
+		}
+		recent = append(recent, recentPath)
+	}
+	if c.RecentCount >= 0 && len(recent) > c.RecentCount {
+		recent = recent[:c.RecentCount]
+	}
+	c.RecentFiles = recent
+}
+
+// ClearRecent empties RecentFiles, pinned files are kept
+func (c *Config) ClearRecent() {
+	c.RecentFiles = nil
+}
+
+// PruneRecent removes recent files that no longer exist, returning true if any were removed.
+// Files that can't be checked, like those on an offline network drive, are kept
+func (c *Config) PruneRecent() bool {
+	recent := []string{}
+	for _, path := range c.RecentFiles {
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		recent = append(recent, path)
+	}
+	isPruned := len(recent) != len(c.RecentFiles)
+	c.RecentFiles = recent
+	return isPruned
+}
+
+// IsPinned returns true if path is a pinned favorite
+func (c *Config) IsPinned(path string) bool {
+	for _, pinnedPath := range c.PinnedFiles {
+		if strings.EqualFold(pinnedPath, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPinned adds or removes path from PinnedFiles
+func (c *Config) SetPinned(path string, isPinned bool) {
+	pinned := []string{}
+	for _, pinnedPath := range c.PinnedFiles {
+		if strings.EqualFold(pinnedPath, path) {
+			continue
+		}
+		pinned = append(pinned, pinnedPath)
+	}
+	if isPinned {
+		pinned = append(pinned, path)
+	}
+	c.PinnedFiles = pinned
+}
+
+func joinPaths(paths []string) string {
+	return strings.Join(paths, pathSeparator)
+}
+
+func splitPaths(value string) []string {
+	paths := []string{}
+	for _, path := range strings.Split(value, pathSeparator) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
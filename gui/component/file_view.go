@@ -1,7 +1,9 @@
 package component
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/xackery/quail-gui/slog"
 	"github.com/xackery/wlk/walk"
@@ -12,7 +14,9 @@ type FileView struct {
 	walk.SorterBase
 	sortColumn int
 	sortOrder  walk.SortOrder
-	items      []*FileViewEntry
+	items      []*FileViewEntry // visible rows
+	all        []*FileViewEntry
+	filter     *fileFilter
 }
 
 func NewFileView() *FileView {
@@ -38,6 +42,20 @@ func (m *FileView) Value(row, col int) interface{} {
 		return item.Ext
 	case 2:
 		return item.Size
+	case 3:
+		return fmt.Sprintf("%08X", item.CRC)
+	case 4:
+		if item.RawSize == 0 || item.Ratio < 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.0f%%", item.Ratio*100)
+	case 5:
+		return item.Type
+	case 6:
+		if item.IsEdited {
+			return "Yes"
+		}
+		return ""
 	}
 
 	slog.Printf("invalid col: %d\n", col)
@@ -60,38 +78,44 @@ func (m *FileView) SetChecked(row int, checked bool) error {
 func (m *FileView) Sort(col int, order walk.SortOrder) error {
 	m.sortColumn, m.sortOrder = col, order
 
-	sort.SliceStable(m.items, func(i, j int) bool {
-		a, b := m.items[i], m.items[j]
-
-		c := func(ls bool) bool {
-			if m.sortOrder == walk.SortAscending {
-				return ls
-			}
-
-			return !ls
-		}
-
+	less := func(a, b *FileViewEntry) bool {
 		switch m.sortColumn {
 		case -1:
 			return false
 		case 0:
-			return c(a.Ext < b.Ext)
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
 		case 1:
-			return c(a.Name < b.Name)
+			return a.Ext < b.Ext
 		case 2:
-			return c(a.Ext < b.Ext)
+			return a.RawSize < b.RawSize
 		case 3:
-			return c(a.RawSize < b.RawSize)
+			return a.CRC < b.CRC
+		case 4:
+			return a.Ratio < b.Ratio
+		case 5:
+			return a.Type < b.Type
+		case 6:
+			return !a.IsEdited && b.IsEdited
 		}
 
 		slog.Printf("invalid sort col: %d", m.sortColumn)
 		return false
+	}
+
+	// descending swaps the operands rather than negating, so equal rows keep their order
+	sort.SliceStable(m.all, func(i, j int) bool {
+		if m.sortOrder == walk.SortAscending {
+			return less(m.all[i], m.all[j])
+		}
+		return less(m.all[j], m.all[i])
 	})
+	m.applyFilter()
 
 	return m.SorterBase.Sort(col, order)
 }
 
 func (m *FileView) ResetRows() {
+	m.all = nil
 	m.items = nil
 
 	m.PublishRowsReset()
@@ -100,7 +124,8 @@ func (m *FileView) ResetRows() {
 }
 
 func (m *FileView) SetItems(items []*FileViewEntry) {
-	m.items = items
+	m.all = items
+	m.applyFilter()
 
 	m.PublishRowsReset()
 
@@ -108,13 +133,71 @@ func (m *FileView) SetItems(items []*FileViewEntry) {
 }
 
 func (m *FileView) AddItem(item *FileViewEntry) {
-	m.items = append(m.items, item)
+	m.all = append(m.all, item)
+	m.applyFilter()
 
 	m.PublishRowsReset()
 
 	m.Sort(m.sortColumn, m.sortOrder)
 }
 
+// SetRatios fills in the ratio of rows measured after they were added
+func (m *FileView) SetRatios(ratios map[*FileViewEntry]float64) {
+	if len(ratios) == 0 {
+		return
+	}
+	for item, ratio := range ratios {
+		item.Ratio = ratio
+	}
+	if m.sortColumn == 4 {
+		m.Sort(m.sortColumn, m.sortOrder)
+	}
+	if len(m.items) > 0 {
+		m.PublishRowsChanged(0, len(m.items)-1)
+	}
+}
+
+// SetFilter hides every row not matching query, an empty query shows all rows
+func (m *FileView) SetFilter(query string) error {
+	filter, err := parseFileFilter(query)
+	if err != nil {
+		return err
+	}
+	m.filter = filter
+	if len(filter.terms) == 0 {
+		m.filter = nil
+	}
+	m.applyFilter()
+
+	m.PublishRowsReset()
+
+	return nil
+}
+
+// IsFiltered returns true if a filter is hiding rows
+func (m *FileView) IsFiltered() bool {
+	return m.filter != nil
+}
+
+// TotalCount returns the number of rows including those hidden by the filter
+func (m *FileView) TotalCount() int {
+	return len(m.all)
+}
+
+// applyFilter rebuilds the visible rows from all rows
+func (m *FileView) applyFilter() {
+	if m.filter == nil {
+		m.items = m.all
+		return
+	}
+	m.items = []*FileViewEntry{}
+	for _, item := range m.all {
+		if m.filter.Match(item) {
+			m.items = append(m.items, item)
+		}
+	}
+}
+
 func (m *FileView) Item(row int) *FileViewEntry {
 	return m.items[row]
 }
@@ -140,7 +223,14 @@ func (m *FileView) ItemByName(name string) (int, *FileViewEntry) {
 }
 
 func (m *FileView) RemoveItem(row int) {
-	m.items = append(m.items[:row], m.items[row+1:]...)
+	item := m.items[row]
+	for i := range m.all {
+		if m.all[i] == item {
+			m.all = append(m.all[:i], m.all[i+1:]...)
+			break
+		}
+	}
+	m.applyFilter()
 
 	m.PublishRowsReset()
 
@@ -3,10 +3,14 @@ package component
 import "github.com/xackery/wlk/walk"
 
 type FileViewEntry struct {
-	Icon    *walk.Icon
-	Name    string
-	Ext     string
-	Size    string
-	RawSize int
-	checked bool
+	Icon     *walk.Icon
+	Name     string
+	Ext      string
+	Size     string
+	RawSize  int
+	CRC      uint32
+	Ratio    float64 // compressed size as a fraction of RawSize, -1 until measured
+	Type     string
+	IsEdited bool
+	checked  bool
 }
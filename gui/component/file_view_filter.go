@@ -0,0 +1,118 @@
+package component
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// fileFilter is a parsed filter query, every term must match for an entry to be shown
+type fileFilter struct {
+	terms []func(item *FileViewEntry) bool
+}
+
+// parseFileFilter parses a space separated query. Supported terms are a
+// substring, a glob like *.dds, ext:wld, and size comparisons like size>1MB
+func parseFileFilter(query string) (*fileFilter, error) {
+	f := &fileFilter{}
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		term := term // each term closure keeps its own copy
+		switch {
+		case strings.HasPrefix(term, "ext:"):
+			ext := strings.TrimPrefix(strings.TrimPrefix(term, "ext:"), ".")
+			if ext == "" {
+				return nil, fmt.Errorf("ext: missing extension")
+			}
+			f.terms = append(f.terms, func(item *FileViewEntry) bool {
+				return strings.TrimPrefix(strings.ToLower(item.Ext), ".") == ext
+			})
+		case len(term) > 4 && strings.HasPrefix(term, "size") && strings.ContainsRune("<>=", rune(term[4])):
+			match, err := parseSizeTerm(strings.TrimPrefix(term, "size"))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", term, err)
+			}
+			f.terms = append(f.terms, match)
+		case strings.ContainsAny(term, "*?["):
+			_, err := filepath.Match(term, "")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", term, err)
+			}
+			f.terms = append(f.terms, func(item *FileViewEntry) bool {
+				isMatch, _ := filepath.Match(term, itemName(item))
+				return isMatch
+			})
+		default:
+			f.terms = append(f.terms, func(item *FileViewEntry) bool {
+				return strings.Contains(itemName(item), term)
+			})
+		}
+	}
+	return f, nil
+}
+
+// Match returns true if every term of the filter matches item
+func (f *fileFilter) Match(item *FileViewEntry) bool {
+	for _, term := range f.terms {
+		if !term(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// itemName returns the lowercase name of item without the edited marker
+func itemName(item *FileViewEntry) string {
+	return strings.TrimSuffix(strings.ToLower(item.Name), "*")
+}
+
+// parseSizeTerm parses the comparison after size, e.g. >1mb or <=512
+func parseSizeTerm(term string) (func(item *FileViewEntry) bool, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("expected >, <, >=, <= or =")
+	}
+
+	value := strings.TrimPrefix(term, op)
+	multiplier := 1
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int
+	}{
+		{"gb", 1 << 30},
+		{"mb", 1 << 20},
+		{"kb", 1 << 10},
+		{"b", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSuffix(value, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size %s", value)
+	}
+	limit := int(size * float64(multiplier))
+
+	return func(item *FileViewEntry) bool {
+		switch op {
+		case ">=":
+			return item.RawSize >= limit
+		case "<=":
+			return item.RawSize <= limit
+		case ">":
+			return item.RawSize > limit
+		case "<":
+			return item.RawSize < limit
+		}
+		return item.RawSize == limit
+	}, nil
+}
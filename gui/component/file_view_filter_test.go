@@ -0,0 +1,49 @@
+package component
+
+import "testing"
+
+func TestParseFileFilter(t *testing.T) {
+	entries := []*FileViewEntry{
+		{Name: "zone.wld", Ext: ".wld", RawSize: 2 << 20},
+		{Name: "Brick.DDS*", Ext: ".dds", RawSize: 512},
+		{Name: "brick2.bmp", Ext: ".bmp", RawSize: 1024},
+	}
+	tests := []struct {
+		query   string
+		wantErr bool
+		want    []bool // match of each entry
+	}{
+		{query: "", want: []bool{true, true, true}},
+		{query: "brick", want: []bool{false, true, true}},
+		{query: "BRICK", want: []bool{false, true, true}},
+		{query: "*.dds", want: []bool{false, true, false}},
+		{query: "brick?.bmp", want: []bool{false, false, true}},
+		{query: "ext:wld", want: []bool{true, false, false}},
+		{query: "ext:.bmp", want: []bool{false, false, true}},
+		{query: "size>1mb", want: []bool{true, false, false}},
+		{query: "size<=1kb", want: []bool{false, true, true}},
+		{query: "size=512", want: []bool{false, true, false}},
+		{query: "size>=0.5kb size<1kb", want: []bool{false, true, false}},
+		{query: "brick ext:bmp", want: []bool{false, false, true}},
+		{query: "ext:", wantErr: true},
+		{query: "size>", wantErr: true},
+		{query: "size>big", wantErr: true},
+		{query: "[a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := parseFileFilter(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i, entry := range entries {
+				if got := f.Match(entry); got != tt.want[i] {
+					t.Errorf("match %s = %v, want %v", entry.Name, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package component
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+	"github.com/xackery/wlk/win"
+)
+
+// TextProblem is an error in the edited text, Line is 1 based and 0 when not tied to a line
+type TextProblem struct {
+	Line    int
+	Message string
+}
+
+// TextEditor is a text box with find and replace, go to line and a caret position indicator
+type TextEditor struct {
+	OnSave func() // called on ctrl+s or ctrl+enter
+
+	text        string
+	isCRLF      bool
+	edit        *walk.TextEdit
+	leFind      *walk.LineEdit
+	leReplace   *walk.LineEdit
+	chkRegex    *walk.CheckBox
+	neLine      *walk.NumberEdit
+	lblPosition *walk.Label
+	lbProblems  *walk.ListBox
+	problems    []TextProblem
+}
+
+// NewTextEditor returns an editor for text, keeping its line endings when read back
+func NewTextEditor(text string) *TextEditor {
+	return &TextEditor{
+		text:   text,
+		isCRLF: strings.Contains(text, "\r\n"),
+	}
+}
+
+// Widget returns the declaration of the editor to place in a dialog
+func (te *TextEditor) Widget() cpl.Widget {
+	return cpl.Composite{
+		Layout: cpl.VBox{MarginsZero: true},
+		Children: []cpl.Widget{
+			cpl.Composite{
+				Layout: cpl.HBox{MarginsZero: true},
+				Children: []cpl.Widget{
+					cpl.Label{Text: "Find:"},
+					cpl.LineEdit{AssignTo: &te.leFind, OnKeyDown: te.onFindKeyDown},
+					cpl.Label{Text: "Replace:"},
+					cpl.LineEdit{AssignTo: &te.leReplace, OnKeyDown: te.onFindKeyDown},
+					cpl.CheckBox{AssignTo: &te.chkRegex, Text: "Regex"},
+					cpl.PushButton{Text: "Find Next", OnClicked: te.FindNext},
+					cpl.PushButton{Text: "Replace", OnClicked: te.Replace},
+					cpl.PushButton{Text: "Replace All", OnClicked: te.ReplaceAll},
+				},
+			},
+			cpl.TextEdit{
+				AssignTo:  &te.edit,
+				Text:      toCRLF(te.text),
+				Font:      cpl.Font{Family: "Consolas", PointSize: 10},
+				VScroll:   true,
+				HScroll:   true,
+				OnKeyDown: te.onKeyDown,
+				OnKeyUp:   func(key walk.Key) { te.updatePosition() },
+				OnMouseUp: func(x, y int, button walk.MouseButton) { te.updatePosition() },
+			},
+			cpl.ListBox{
+				AssignTo:              &te.lbProblems,
+				Visible:               false,
+				MaxSize:               cpl.Size{Height: 100},
+				OnCurrentIndexChanged: te.onProblem,
+				OnItemActivated:       te.onProblem,
+			},
+			cpl.Composite{
+				Layout: cpl.HBox{MarginsZero: true},
+				Children: []cpl.Widget{
+					cpl.Label{AssignTo: &te.lblPosition, Text: "Ln 1, Col 1"},
+					cpl.HSpacer{},
+					cpl.Label{Text: "Go to line:"},
+					cpl.NumberEdit{AssignTo: &te.neLine, MinValue: 1, MaxValue: 1 << 30, Value: 1.0},
+					cpl.PushButton{Text: "Go", OnClicked: func() { te.GoToLine(int(te.neLine.Value())) }},
+				},
+			},
+		},
+	}
+}
+
+// Text returns the edited text with its original line endings
+func (te *TextEditor) Text() string {
+	text := te.edit.Text()
+	if !te.isCRLF {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	return text
+}
+
+// GoToLine moves the caret to the start of a 1 based line
+func (te *TextEditor) GoToLine(line int) {
+	text := te.edit.Text()
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.Index(text[offset:], "\n")
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+	te.selectBytes(text, offset, offset)
+	te.edit.SetFocus()
+}
+
+// SetProblems lists problems below the text, clicking one jumps to its line.
+// An empty list hides the panel
+func (te *TextEditor) SetProblems(problems []TextProblem) {
+	te.problems = problems
+	items := []string{}
+	for _, problem := range problems {
+		if problem.Line == 0 {
+			items = append(items, problem.Message)
+			continue
+		}
+		items = append(items, fmt.Sprintf("Line %d: %s", problem.Line, problem.Message))
+	}
+	err := te.lbProblems.SetModel(items)
+	if err != nil {
+		te.setStatus(fmt.Sprintf("problems: %s", err))
+	}
+	te.lbProblems.SetVisible(len(problems) > 0)
+}
+
+func (te *TextEditor) onProblem() {
+	idx := te.lbProblems.CurrentIndex()
+	if idx < 0 || idx >= len(te.problems) || te.problems[idx].Line == 0 {
+		return
+	}
+	te.GoToLine(te.problems[idx].Line)
+}
+
+// FindNext selects the next match after the caret, wrapping to the start
+func (te *TextEditor) FindNext() {
+	re, err := te.pattern()
+	if err != nil {
+		te.setStatus(err.Error())
+		return
+	}
+	text := te.edit.Text()
+	_, end := te.edit.TextSelection()
+	from := byteOffset(text, end)
+
+	loc := re.FindStringIndex(text[from:])
+	if loc != nil && loc[0] == loc[1] {
+		loc = nil
+	}
+	if loc != nil {
+		loc[0] += from
+		loc[1] += from
+	} else {
+		loc = re.FindStringIndex(text)
+		if loc == nil || loc[0] == loc[1] {
+			te.setStatus(fmt.Sprintf("%s not found", te.leFind.Text()))
+			return
+		}
+	}
+	te.selectBytes(text, loc[0], loc[1])
+}
+
+// Replace replaces the selection if it matches, then finds the next match
+func (te *TextEditor) Replace() {
+	re, err := te.pattern()
+	if err != nil {
+		te.setStatus(err.Error())
+		return
+	}
+	text := te.edit.Text()
+	start, end := te.edit.TextSelection()
+	selected := text[byteOffset(text, start):byteOffset(text, end)]
+	loc := re.FindStringIndex(selected)
+	if selected != "" && loc != nil && loc[0] == 0 && loc[1] == len(selected) {
+		te.edit.ReplaceSelectedText(toCRLF(re.ReplaceAllString(selected, te.replacement())), true)
+	}
+	te.FindNext()
+}
+
+// ReplaceAll replaces every match
+func (te *TextEditor) ReplaceAll() {
+	re, err := te.pattern()
+	if err != nil {
+		te.setStatus(err.Error())
+		return
+	}
+	text := te.edit.Text()
+	count := len(re.FindAllStringIndex(text, -1))
+	if count == 0 {
+		te.setStatus(fmt.Sprintf("%s not found", te.leFind.Text()))
+		return
+	}
+	te.edit.SetText(toCRLF(re.ReplaceAllString(text, te.replacement())))
+	te.setStatus(fmt.Sprintf("Replaced %d", count))
+}
+
+// pattern compiles the find box, literal text is matched case insensitively
+func (te *TextEditor) pattern() (*regexp.Regexp, error) {
+	find := te.leFind.Text()
+	if find == "" {
+		return nil, fmt.Errorf("nothing to find")
+	}
+	if !te.chkRegex.Checked() {
+		find = "(?i)" + regexp.QuoteMeta(find)
+	}
+	re, err := regexp.Compile("(?m)" + find)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+	return re, nil
+}
+
+func (te *TextEditor) replacement() string {
+	if te.chkRegex.Checked() {
+		return te.leReplace.Text()
+	}
+	return strings.ReplaceAll(te.leReplace.Text(), "$", "$$")
+}
+
+// selectBytes selects text between two byte offsets and scrolls to it
+func (te *TextEditor) selectBytes(text string, start int, end int) {
+	te.edit.SetTextSelection(utf16Offset(text, start), utf16Offset(text, end))
+	te.edit.ScrollToCaret()
+	te.updatePosition()
+}
+
+// updatePosition shows the line and column of the caret. It asks the edit
+// control rather than reading the text, as it runs on every key and click
+func (te *TextEditor) updatePosition() {
+	_, end := te.edit.TextSelection()
+	line := int(te.edit.SendMessage(win.EM_LINEFROMCHAR, uintptr(end), 0))
+	lineStart := int(te.edit.SendMessage(win.EM_LINEINDEX, uintptr(line), 0))
+	te.setStatus(fmt.Sprintf("Ln %d, Col %d", line+1, end-lineStart+1))
+}
+
+func (te *TextEditor) setStatus(text string) {
+	te.lblPosition.SetText(text)
+}
+
+func (te *TextEditor) onKeyDown(key walk.Key) {
+	modifiers := walk.ModifiersDown()
+	switch {
+	case (key == walk.KeyS || key == walk.KeyReturn) && modifiers == walk.ModControl:
+		if te.OnSave != nil {
+			te.OnSave()
+		}
+	case key == walk.KeyF && modifiers == walk.ModControl:
+		te.leFind.SetFocus()
+		te.leFind.SetTextSelection(0, -1)
+	case key == walk.KeyH && modifiers == walk.ModControl:
+		te.leReplace.SetFocus()
+		te.leReplace.SetTextSelection(0, -1)
+	case key == walk.KeyG && modifiers == walk.ModControl:
+		te.neLine.SetFocus()
+	case key == walk.KeyF3:
+		te.FindNext()
+	}
+}
+
+func (te *TextEditor) onFindKeyDown(key walk.Key) {
+	switch {
+	case key == walk.KeyReturn || key == walk.KeyF3:
+		te.FindNext()
+	case key == walk.KeyEscape:
+		te.edit.SetFocus()
+	default:
+		te.onKeyDown(key)
+	}
+}
+
+// toCRLF converts line endings for the edit control
+func toCRLF(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
+
+// utf16Offset converts a byte offset of text to the utf16 offset the edit control uses
+func utf16Offset(text string, offset int) int {
+	return len(utf16.Encode([]rune(text[:offset])))
+}
+
+// byteOffset converts a utf16 offset of the edit control to a byte offset of text
+func byteOffset(text string, offset int) int {
+	count := 0
+	for i, r := range text {
+		if count >= offset {
+			return i
+		}
+		count += len(utf16.Encode([]rune{r}))
+	}
+	return len(text)
+}
@@ -0,0 +1,69 @@
+package gui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-gui/gui/dialog"
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/quail-gui/texture"
+	"github.com/xackery/wlk/walk"
+)
+
+var (
+	menuEntryConvertTexture *walk.Action
+)
+
+// targetLabels describes texture.Targets for the convert dialog
+var targetLabels = map[string]string{
+	texture.TargetDXT1: "DDS (DXT1, 1-bit alpha)",
+	texture.TargetDXT5: "DDS (DXT5, full alpha)",
+	texture.TargetBMP:  "BMP (8-bit paletted)",
+	texture.TargetPNG:  "PNG",
+}
+
+func onMenuEntryConvertTexture() {
+	if current == nil {
+		return
+	}
+	names := []string{}
+	for _, name := range selectedNames() {
+		if texture.IsTextureExt(filepath.Ext(name)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		popup.Errorf(mw, "convert texture: select a texture entry")
+		return
+	}
+
+	labels := []string{}
+	for _, target := range texture.Targets {
+		labels = append(labels, targetLabels[target])
+	}
+	idx, isUpdateRefs, err := dialog.ShowConvertTexture(mw, names, labels)
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(mw, "convert texture: %s", err)
+		return
+	}
+	target := texture.Targets[idx]
+
+	for _, name := range names {
+		newName, updated, err := current.ConvertTexture(name, target, isUpdateRefs)
+		if err != nil {
+			popup.Errorf(mw, "convert %s: %s", name, err)
+			break
+		}
+		slog.Printf("Converted %s to %s\n", name, newName)
+		if len(updated) > 0 {
+			slog.Printf("Updated references in %s\n", strings.Join(updated, ", "))
+		}
+	}
+	refreshEntries()
+	updateJumps()
+	updateTitle()
+}
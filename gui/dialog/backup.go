@@ -0,0 +1,81 @@
+package dialog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// ShowRestoreBackup lets the user pick one of the backups of an archive, returning its path
+func ShowRestoreBackup(mw *walk.MainWindow, title string, backups []string) (string, error) {
+	var restorePB, cancelPB *walk.PushButton
+
+	items := []string{}
+	for _, path := range backups {
+		item := filepath.Base(path)
+		fi, err := os.Stat(path)
+		if err == nil {
+			item = fmt.Sprintf("%s (%s, %d bytes)", item, fi.ModTime().Format("2006-01-02 15:04:05"), fi.Size())
+		}
+		items = append(items, item)
+	}
+
+	var lbBackup *walk.ListBox
+	var dlg *walk.Dialog
+	selected := ""
+	onRestore := func() {
+		idx := lbBackup.CurrentIndex()
+		if idx < 0 || idx >= len(backups) {
+			popup.Errorf(dlg, "restore: select a backup")
+			return
+		}
+		selected = backups[idx]
+		dlg.Accept()
+	}
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         fmt.Sprintf("Restore %s", title),
+		DefaultButton: &restorePB,
+		CancelButton:  &cancelPB,
+		MinSize:       cpl.Size{Width: 400, Height: 300},
+		Layout:        cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Label{Text: "Replace the archive on disk with a backup:"},
+			cpl.ListBox{
+				AssignTo:        &lbBackup,
+				Model:           items,
+				CurrentIndex:    0,
+				OnItemActivated: onRestore,
+			},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					cpl.PushButton{
+						AssignTo:  &restorePB,
+						Text:      "Restore",
+						OnClicked: onRestore,
+					},
+				},
+			},
+		},
+	}
+	result, err := dia.Run(mw)
+	if err != nil {
+		return "", fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return "", fmt.Errorf("cancelled")
+	}
+	return selected, nil
+}
@@ -0,0 +1,107 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/session"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// ShowBulkRename asks for a find and replace over names and returns the resulting renames
+func ShowBulkRename(mw *walk.MainWindow, names []string) ([]session.Rename, error) {
+	var renamePB, cancelPB *walk.PushButton
+	var leMatch, leFind, leReplace *walk.LineEdit
+	var chkRegex *walk.CheckBox
+	var lbPreview *walk.ListBox
+	var lblPreview *walk.Label
+
+	var renames []session.Rename
+	var dlg *walk.Dialog
+	onPreview := func() error {
+		var err error
+		renames, err = session.BulkRename(names, leMatch.Text(), leFind.Text(), leReplace.Text(), chkRegex.Checked())
+		if err != nil {
+			renames = nil
+			lblPreview.SetText(fmt.Sprintf("Preview: %s", err))
+			lbPreview.SetModel([]string{})
+			return err
+		}
+		lines := []string{}
+		for _, rename := range renames {
+			lines = append(lines, fmt.Sprintf("%s -> %s", rename.From, rename.To))
+		}
+		lblPreview.SetText(fmt.Sprintf("Preview: %d of %d entries renamed", len(renames), len(names)))
+		lbPreview.SetModel(lines)
+		return nil
+	}
+	onChange := func() {
+		if lbPreview == nil {
+			// widgets are still being created
+			return
+		}
+		onPreview()
+	}
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         fmt.Sprintf("Rename %d entries", len(names)),
+		DefaultButton: &renamePB,
+		CancelButton:  &cancelPB,
+		MinSize:       cpl.Size{Width: 400, Height: 350},
+		Layout:        cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Composite{
+				Layout: cpl.Grid{Columns: 2},
+				Children: []cpl.Widget{
+					cpl.Label{Text: "Only entries matching:", ToolTipText: "Glob such as *.bmp, leave empty for every entry"},
+					cpl.LineEdit{AssignTo: &leMatch, Text: "*", OnTextChanged: onChange},
+					cpl.Label{Text: "Find:"},
+					cpl.LineEdit{AssignTo: &leFind, OnTextChanged: onChange},
+					cpl.Label{Text: "Replace with:"},
+					cpl.LineEdit{AssignTo: &leReplace, OnTextChanged: onChange},
+					cpl.Label{Text: "Regular expression:"},
+					cpl.CheckBox{AssignTo: &chkRegex, OnCheckedChanged: onChange},
+				},
+			},
+			cpl.Label{AssignTo: &lblPreview, Text: "Preview:"},
+			cpl.ListBox{AssignTo: &lbPreview},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					cpl.PushButton{
+						AssignTo: &renamePB,
+						Text:     "Rename",
+						OnClicked: func() {
+							err := onPreview()
+							if err != nil {
+								popup.Errorf(dlg, "rename: %s", err)
+								return
+							}
+							if len(renames) == 0 {
+								popup.Errorf(dlg, "rename: no entries match")
+								return
+							}
+							dlg.Accept()
+						},
+					},
+				},
+			},
+		},
+	}
+	result, err := dia.Run(mw)
+	if err != nil {
+		return nil, fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return nil, fmt.Errorf("cancelled")
+	}
+	return renames, nil
+}
@@ -0,0 +1,84 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// ShowConvertTexture lets the user pick the format to convert textures to, returning
+// the index of the chosen target and whether material references should be updated
+func ShowConvertTexture(mw *walk.MainWindow, names []string, targets []string) (int, bool, error) {
+	var convertPB, cancelPB *walk.PushButton
+
+	var cbTarget *walk.ComboBox
+	var chkRefs *walk.CheckBox
+	var dlg *walk.Dialog
+	selected := -1
+	isUpdateRefs := false
+	onConvert := func() {
+		idx := cbTarget.CurrentIndex()
+		if idx < 0 || idx >= len(targets) {
+			popup.Errorf(dlg, "convert texture: select a format")
+			return
+		}
+		selected = idx
+		isUpdateRefs = chkRefs.Checked()
+		dlg.Accept()
+	}
+
+	title := names[0]
+	if len(names) > 1 {
+		title = fmt.Sprintf("%d textures", len(names))
+	}
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         fmt.Sprintf("Convert %s", title),
+		DefaultButton: &convertPB,
+		CancelButton:  &cancelPB,
+		MinSize:       cpl.Size{Width: 350, Height: 150},
+		Layout:        cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Label{Text: "Convert " + strings.Join(names, ", ") + " to:"},
+			cpl.ComboBox{
+				AssignTo:     &cbTarget,
+				Model:        targets,
+				CurrentIndex: 0,
+			},
+			cpl.CheckBox{
+				AssignTo: &chkRefs,
+				Text:     "Rename references in .mod, .mds, .ter and .wld materials",
+				Checked:  true,
+			},
+			cpl.VSpacer{},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					cpl.PushButton{
+						AssignTo:  &convertPB,
+						Text:      "Convert",
+						OnClicked: onConvert,
+					},
+				},
+			},
+		},
+	}
+	result, err := dia.Run(mw)
+	if err != nil {
+		return -1, false, fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return -1, false, fmt.Errorf("cancelled")
+	}
+	return selected, isUpdateRefs, nil
+}
@@ -0,0 +1,328 @@
+package dialog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// formField is a labelled line edit, Parse validates the text and stores it
+type formField struct {
+	Label string
+	Text  string
+	Parse func(text string) error
+}
+
+// showFormEdit shows a line edit for every field, followed by any extra widgets,
+// and only closes once every field parses
+func showFormEdit(owner walk.Form, title string, fields []*formField, extra ...cpl.Widget) error {
+	var okPB, cancelPB *walk.PushButton
+	var dlg *walk.Dialog
+
+	edits := make([]*walk.LineEdit, len(fields))
+	grid := []cpl.Widget{}
+	for i, field := range fields {
+		grid = append(grid,
+			cpl.Label{Text: field.Label + ":"},
+			cpl.LineEdit{AssignTo: &edits[i], Text: field.Text},
+		)
+	}
+
+	onOK := func() {
+		for i, field := range fields {
+			err := field.Parse(strings.TrimSpace(edits[i].Text()))
+			if err != nil {
+				popup.Errorf(dlg, "%s: %s", strings.ToLower(field.Label), err)
+				edits[i].SetFocus()
+				return
+			}
+		}
+		dlg.Accept()
+	}
+
+	children := []cpl.Widget{
+		cpl.Composite{
+			Layout:   cpl.Grid{Columns: 2},
+			Children: grid,
+		},
+	}
+	children = append(children, extra...)
+	children = append(children, cpl.Composite{
+		Layout: cpl.HBox{},
+		Children: []cpl.Widget{
+			cpl.HSpacer{},
+			cpl.PushButton{
+				AssignTo:  &cancelPB,
+				Text:      "Cancel",
+				OnClicked: func() { dlg.Cancel() },
+			},
+			cpl.PushButton{
+				AssignTo:  &okPB,
+				Text:      "OK",
+				OnClicked: onOK,
+			},
+		},
+	})
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         title,
+		DefaultButton: &okPB,
+		CancelButton:  &cancelPB,
+		MinSize:       cpl.Size{Width: 350, Height: 100},
+		Layout:        cpl.VBox{},
+		Children:      children,
+	}
+	result, err := dia.Run(owner)
+	if err != nil {
+		return fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return fmt.Errorf("cancelled")
+	}
+	return nil
+}
+
+// collectionEditor is a list with add, edit and delete buttons over a collection.
+// Setting Columns and Rows shows a table instead of Items
+type collectionEditor struct {
+	Title   string
+	Items   func() []string
+	Columns []cpl.TableViewColumn
+	Rows    func() [][]string
+	Add     func(owner walk.Form) error
+	Edit    func(owner walk.Form, idx int) error
+	Delete  func(owner walk.Form, idx int) error
+	lb      *walk.ListBox
+	tv      *walk.TableView
+	rows    *rowsModel
+}
+
+// Widget returns the declaration of the collection for a dialog
+func (c *collectionEditor) Widget() cpl.Widget {
+	var view cpl.Widget
+	if c.Columns != nil {
+		c.rows = &rowsModel{rows: c.Rows()}
+		view = cpl.TableView{
+			AssignTo:         &c.tv,
+			AlternatingRowBG: true,
+			Columns:          c.Columns,
+			Model:            c.rows,
+			RowSpan:          4,
+			MinSize:          cpl.Size{Width: 200, Height: 120},
+			OnItemActivated:  c.onEdit,
+		}
+	} else {
+		view = cpl.ListBox{
+			AssignTo:        &c.lb,
+			Model:           c.Items(),
+			RowSpan:         4,
+			MinSize:         cpl.Size{Width: 200, Height: 80},
+			OnItemActivated: c.onEdit,
+		}
+	}
+	return cpl.GroupBox{
+		Title:  c.Title,
+		Layout: cpl.Grid{Columns: 2},
+		Children: []cpl.Widget{
+			view,
+			cpl.PushButton{Text: "Add", OnClicked: c.onAdd},
+			cpl.PushButton{Text: "Edit", OnClicked: c.onEdit},
+			cpl.PushButton{Text: "Delete", OnClicked: c.onDelete},
+			cpl.VSpacer{},
+		},
+	}
+}
+
+func (c *collectionEditor) form() walk.Form {
+	if c.tv != nil {
+		return c.tv.Form()
+	}
+	return c.lb.Form()
+}
+
+func (c *collectionEditor) currentIndex() int {
+	if c.tv != nil {
+		return c.tv.CurrentIndex()
+	}
+	return c.lb.CurrentIndex()
+}
+
+func (c *collectionEditor) onAdd() {
+	count := 0
+	if c.rows != nil {
+		count = len(c.rows.rows)
+	} else {
+		count = len(c.Items())
+	}
+	c.run("add", func() error { return c.Add(c.form()) }, count)
+}
+
+func (c *collectionEditor) onEdit() {
+	idx := c.currentIndex()
+	if idx < 0 {
+		return
+	}
+	c.run("edit", func() error { return c.Edit(c.form(), idx) }, idx)
+}
+
+func (c *collectionEditor) onDelete() {
+	idx := c.currentIndex()
+	if idx < 0 {
+		return
+	}
+	c.run("delete", func() error { return c.Delete(c.form(), idx) }, idx)
+}
+
+// run applies an action and refreshes the collection, selecting idx
+func (c *collectionEditor) run(action string, fn func() error, idx int) {
+	err := fn()
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(c.form(), "%s %s: %s", action, strings.ToLower(c.Title), err)
+		return
+	}
+	err = c.refresh(idx)
+	if err != nil {
+		popup.Errorf(c.form(), "refresh %s: %s", strings.ToLower(c.Title), err)
+	}
+}
+
+func (c *collectionEditor) refresh(idx int) error {
+	if c.tv != nil {
+		c.rows.rows = c.Rows()
+		c.rows.PublishRowsReset()
+		idx = min(idx, len(c.rows.rows)-1)
+		if idx < 0 {
+			return nil
+		}
+		return c.tv.SetCurrentIndex(idx)
+	}
+	items := c.Items()
+	err := c.lb.SetModel(items)
+	if err != nil {
+		return err
+	}
+	idx = min(idx, len(items)-1)
+	if idx < 0 {
+		return nil
+	}
+	return c.lb.SetCurrentIndex(idx)
+}
+
+// rowsModel is a table model over preformatted cells
+type rowsModel struct {
+	walk.TableModelBase
+	rows [][]string
+}
+
+func (m *rowsModel) RowCount() int {
+	return len(m.rows)
+}
+
+func (m *rowsModel) Value(row, col int) interface{} {
+	return m.rows[row][col]
+}
+
+// versionWidget is the header version combo box of a raw editor
+func versionWidget(cmb **walk.ComboBox, version uint32) cpl.Widget {
+	return cpl.GroupBox{
+		Title:  "Header",
+		Layout: cpl.Grid{Columns: 2},
+		Children: []cpl.Widget{
+			cpl.Label{Text: "Version:"},
+			cpl.ComboBox{
+				AssignTo: cmb,
+				Editable: false,
+				Value:    fmt.Sprintf("%d", version),
+				Model:    []string{"1", "2", "3"},
+			},
+		},
+	}
+}
+
+func formatFloats(values ...float32) string {
+	out := []string{}
+	for _, v := range values {
+		out = append(out, strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	return strings.Join(out, ", ")
+}
+
+// parseFloats parses count comma separated numbers
+func parseFloats(text string, count int) ([]float32, error) {
+	parts := strings.Split(text, ",")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d comma separated numbers", count)
+	}
+	values := []float32{}
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a number", strings.TrimSpace(part))
+		}
+		values = append(values, float32(v))
+	}
+	return values, nil
+}
+
+// parseFloatsInto parses len(dst) comma separated numbers into dst
+func parseFloatsInto(text string, dst []float32) error {
+	values, err := parseFloats(text, len(dst))
+	if err != nil {
+		return err
+	}
+	copy(dst, values)
+	return nil
+}
+
+func formatUint8s(values ...uint8) string {
+	out := []string{}
+	for _, v := range values {
+		out = append(out, strconv.Itoa(int(v)))
+	}
+	return strings.Join(out, ", ")
+}
+
+// parseUint8s parses count comma separated bytes
+func parseUint8s(text string, count int) ([]uint8, error) {
+	parts := strings.Split(text, ",")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d comma separated values", count)
+	}
+	values := []uint8{}
+	for _, part := range parts {
+		v, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not between 0 and 255", strings.TrimSpace(part))
+		}
+		values = append(values, uint8(v))
+	}
+	return values, nil
+}
+
+// parseIndex parses an index in [min, max)
+func parseIndex(text string, min int, max int) (int, error) {
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a whole number", text)
+	}
+	if v < min || v >= max {
+		return 0, fmt.Errorf("%d is out of range, expected %d to %d", v, min, max-1)
+	}
+	return v, nil
+}
+
+func parseUint32(text string) (uint32, error) {
+	v, err := strconv.ParseUint(text, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a whole number", text)
+	}
+	return uint32(v), nil
+}
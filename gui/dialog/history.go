@@ -0,0 +1,79 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/session"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// ShowHistory lists the changes made since the last save and lets the user pick
+// a point to undo or redo to, returning how many changes should stay applied
+func ShowHistory(mw *walk.MainWindow, title string, done []*session.Change, undone []*session.Change) (int, error) {
+	var revertPB, closePB *walk.PushButton
+
+	items := []string{"(last save)"}
+	for _, change := range done {
+		items = append(items, fmt.Sprintf("%s  %s", change.Time.Format("15:04:05"), change.Title))
+	}
+	for _, change := range undone {
+		items = append(items, fmt.Sprintf("%s  %s (undone)", change.Time.Format("15:04:05"), change.Title))
+	}
+
+	var lbHistory *walk.ListBox
+	var dlg *walk.Dialog
+	selected := len(done)
+	onRevert := func() {
+		idx := lbHistory.CurrentIndex()
+		if idx < 0 || idx >= len(items) {
+			popup.Errorf(dlg, "history: select a change")
+			return
+		}
+		selected = idx
+		dlg.Accept()
+	}
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         fmt.Sprintf("%s History", title),
+		DefaultButton: &revertPB,
+		CancelButton:  &closePB,
+		MinSize:       cpl.Size{Width: 400, Height: 300},
+		Layout:        cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Label{Text: "Changes since the last save, pick one to undo or redo to it:"},
+			cpl.ListBox{
+				AssignTo:        &lbHistory,
+				Model:           items,
+				CurrentIndex:    len(done),
+				OnItemActivated: onRevert,
+			},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &closePB,
+						Text:      "Close",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					cpl.PushButton{
+						AssignTo:  &revertPB,
+						Text:      "Go To Change",
+						OnClicked: onRevert,
+					},
+				},
+			},
+		},
+	}
+	result, err := dia.Run(mw)
+	if err != nil {
+		return 0, fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return 0, fmt.Errorf("cancelled")
+	}
+	return selected, nil
+}
@@ -0,0 +1,99 @@
+package dialog
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// Import conflict resolutions returned by ShowImportConflict
+const (
+	ConflictReplace = iota
+	ConflictSkip
+	ConflictRename
+)
+
+// ShowImportConflict asks what to do with an imported file whose name is already
+// used in the archive. It returns the resolution, the new name when renaming,
+// and whether the resolution applies to every remaining conflict
+func ShowImportConflict(mw *walk.MainWindow, name string, suggestedName string, remaining int) (int, string, bool, error) {
+	var replacePB, skipPB, renamePB, cancelPB *walk.PushButton
+	var leName *walk.LineEdit
+	var chkApplyAll *walk.CheckBox
+
+	var dlg *walk.Dialog
+	resolution := ConflictSkip
+	newName := ""
+	onResolve := func(value int) {
+		if value == ConflictRename {
+			newName = leName.Text()
+			if newName == "" || filepath.Ext(newName) == "" {
+				popup.Errorf(dlg, "rename: new name must have an extension")
+				return
+			}
+		}
+		resolution = value
+		dlg.Accept()
+	}
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         "Import conflict",
+		DefaultButton: &replacePB,
+		CancelButton:  &cancelPB,
+		MinSize:       cpl.Size{Width: 350, Height: 150},
+		Layout:        cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Label{Text: fmt.Sprintf("%s already exists in this archive.", name)},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.Label{Text: "Rename to:"},
+					cpl.LineEdit{AssignTo: &leName, Text: suggestedName},
+				},
+			},
+			cpl.CheckBox{
+				AssignTo: &chkApplyAll,
+				Text:     fmt.Sprintf("Apply to the %d remaining conflicts", remaining),
+				Visible:  remaining > 0,
+			},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &replacePB,
+						Text:      "&Replace",
+						OnClicked: func() { onResolve(ConflictReplace) },
+					},
+					cpl.PushButton{
+						AssignTo:  &skipPB,
+						Text:      "&Skip",
+						OnClicked: func() { onResolve(ConflictSkip) },
+					},
+					cpl.PushButton{
+						AssignTo:  &renamePB,
+						Text:      "Re&name",
+						OnClicked: func() { onResolve(ConflictRename) },
+					},
+					cpl.PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+				},
+			},
+		},
+	}
+	result, err := dia.Run(mw)
+	if err != nil {
+		return ConflictSkip, "", false, fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return ConflictSkip, "", false, fmt.Errorf("cancelled")
+	}
+	return resolution, newName, chkApplyAll.Checked(), nil
+}
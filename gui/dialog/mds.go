@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/xackery/quail-gui/ico"
 	"github.com/xackery/quail-gui/popup"
 	"github.com/xackery/quail/raw"
 	"github.com/xackery/wlk/cpl"
@@ -12,149 +11,39 @@ import (
 )
 
 func ShowMdsEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
-
+	var savePB, cancelPB *walk.PushButton
 	data, ok := src.(*raw.Mds)
 	if !ok {
 		return fmt.Errorf("cast mds")
 	}
 
-	var savePB, cancelPB *walk.PushButton
-	formElements := cpl.Composite{
-		Layout:   cpl.VBox{},
-		Children: []cpl.Widget{},
-	}
+	model := newModelData(data.Materials, data.Bones, data.Vertices, data.Triangles)
 
 	var cmbVersion *walk.ComboBox
-	versions := []string{"1", "2", "3"}
-	formElements.Children = append(formElements.Children, cpl.GroupBox{
-		Title:  "Header",
-		Layout: cpl.Grid{Columns: 2},
-		Children: []cpl.Widget{
-			cpl.Label{Text: "Version:"},
-			cpl.ComboBox{
-				AssignTo: &cmbVersion,
-				Editable: false,
-				Value:    fmt.Sprintf("%d", data.Version),
-				Model:    versions,
-			},
-		},
-	})
-
-	materials := []string{}
-	for _, material := range data.Materials {
-		materials = append(materials, material.Name)
-	}
-	formElements.Children = append(formElements.Children, cpl.GroupBox{
-		Title:  "Materials",
-		Layout: cpl.Grid{Columns: 3},
-		Children: []cpl.Widget{
-			cpl.ListBox{
-				Model: materials,
-				OnItemActivated: func() {
-					fmt.Println("item activated: ", data.Materials)
-				},
-			},
-			cpl.PushButton{
-				Image: ico.Grab("new"),
-			},
-			cpl.PushButton{
-				Image: ico.Grab("edit"),
-			},
-		},
-	})
-
-	defaultBone := ""
-	if len(data.Bones) > 0 {
-		defaultBone = data.Bones[0].Name
-	}
-	bones := []string{}
-	for _, bone := range data.Bones {
-		bones = append(bones, bone.Name)
-	}
-	formElements.Children = append(formElements.Children, cpl.GroupBox{
-		Title:  "Bones",
-		Layout: cpl.Grid{Columns: 3},
-		Children: []cpl.Widget{
-			cpl.ComboBox{
-				Editable: false,
-				Value:    defaultBone,
-				Model:    bones,
-			},
-			cpl.PushButton{
-				Text: "Add",
-			},
-			cpl.PushButton{
-				Text: "Edit",
-			},
-		},
-	})
-
-	defaultVertex := ""
-	if len(data.Vertices) > 0 {
-		defaultVertex = "1"
-	}
-	vertices := []string{}
-	for i := 0; i < len(data.Vertices); i++ {
-		vertices = append(vertices, fmt.Sprintf("%d", i+1))
-	}
-	formElements.Children = append(formElements.Children, cpl.GroupBox{
-		Title:  "Vertices",
-		Layout: cpl.Grid{Columns: 3},
+	formElements := cpl.Composite{
+		Layout: cpl.VBox{},
 		Children: []cpl.Widget{
-			cpl.ComboBox{
-				Editable: false,
-				Value:    defaultVertex,
-				Model:    vertices,
-			},
-			cpl.PushButton{
-				Text: "Add",
-			},
-			cpl.PushButton{
-				Text: "Edit",
-			},
+			versionWidget(&cmbVersion, data.Version),
 		},
-	})
-
-	defaultTriangle := ""
-	if len(data.Triangles) > 0 {
-		defaultTriangle = "1"
 	}
-	triangles := []string{}
-	for i := 0; i < len(data.Triangles); i++ {
-		triangles = append(triangles, fmt.Sprintf("%d", i+1))
+	for _, editor := range model.editors() {
+		formElements.Children = append(formElements.Children, editor.Widget())
 	}
-
-	formElements.Children = append(formElements.Children, cpl.GroupBox{
-		Title:  "Triangles",
-		Layout: cpl.Grid{Columns: 3},
-		Children: []cpl.Widget{
-			cpl.ComboBox{
-				Editable: false,
-				Value:    defaultTriangle,
-				Model:    triangles,
-			},
-			cpl.PushButton{
-				Text: "Add",
-			},
-			cpl.PushButton{
-				Text: "Edit",
-			},
-		},
-	})
+	formElements.Children = append(formElements.Children, model.meshWidget())
+	// per-vertex weight editing is blocked on quail: raw.Vertex carries no bone
+	// weights, see Known limitations in the README
+	formElements.Children = append(formElements.Children, cpl.Label{Text: "Vertex bone weights can't be edited, quail doesn't expose them yet."})
 
 	onSave := func() error {
-		newVersionStr := cmbVersion.Text()
-		if newVersionStr == "" {
-			return fmt.Errorf("version is required")
-		}
-		newVersion, err := strconv.Atoi(newVersionStr)
+		newVersion, err := strconv.Atoi(cmbVersion.Text())
 		if err != nil {
 			return fmt.Errorf("parse version: %w", err)
 		}
-		if data.Version != uint32(newVersion) {
-			data.Version = uint32(newVersion)
-		}
-		fmt.Println("new version:", data.Version)
+		data.Version = uint32(newVersion)
+		data.Materials = model.Materials
+		data.Bones = model.Bones
+		data.Vertices = model.Vertices
+		data.Triangles = model.Triangles
 		return nil
 	}
 
@@ -197,7 +86,6 @@ func ShowMdsEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 	if err != nil {
 		return fmt.Errorf("run dialog: %w", err)
 	}
-
 	if result != walk.DlgCmdOK {
 		return fmt.Errorf("cancelled")
 	}
@@ -0,0 +1,845 @@
+package dialog
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail/raw"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// meshWidget summarises the geometry of m with a button to open the mesh grid
+func (m *modelData) meshWidget() cpl.Widget {
+	var lblSummary *walk.Label
+	summary := func() string {
+		return fmt.Sprintf("%d vertices, %d triangles", len(m.Vertices), len(m.Triangles))
+	}
+	return cpl.GroupBox{
+		Title:  "Mesh",
+		Layout: cpl.HBox{},
+		Children: []cpl.Widget{
+			cpl.Label{AssignTo: &lblSummary, Text: summary()},
+			cpl.HSpacer{},
+			cpl.PushButton{
+				Text: "Edit Mesh...",
+				OnClicked: func() {
+					err := m.showMesh(lblSummary.Form())
+					if err != nil {
+						if err.Error() == "cancelled" {
+							return
+						}
+						popup.Errorf(lblSummary.Form(), "edit mesh: %s", err)
+						return
+					}
+					lblSummary.SetText(summary())
+				},
+			},
+		},
+	}
+}
+
+// showMesh edits the vertices and triangles of m in a grid, materials are read only
+func (m *modelData) showMesh(owner walk.Form) error {
+	var okPB, cancelPB *walk.PushButton
+	var dlg *walk.Dialog
+
+	work := newModelData(nil, nil, m.Vertices, m.Triangles)
+	work.Materials = m.Materials
+
+	vertices := &meshGrid{
+		columns: []cpl.TableViewColumn{
+			{Name: "#", Width: 50},
+			{Name: "Position", Width: 150},
+			{Name: "Normal", Width: 150},
+			{Name: "UV", Width: 100},
+			{Name: "UV2", Width: 100},
+			{Name: "Color", Width: 100},
+		},
+		count:  func() int { return len(work.Vertices) },
+		cells:  work.vertexCells,
+		record: work.vertexRecord,
+		add:    work.addVertex,
+		edit:   work.editVertices,
+		delete: work.deleteVertices,
+		paste:  work.pasteVertices,
+	}
+	triangles := &meshGrid{
+		columns: []cpl.TableViewColumn{
+			{Name: "#", Width: 50},
+			{Name: "Vertices", Width: 150},
+			{Name: "Material", Width: 150},
+			{Name: "Material Index", Width: 90},
+			{Name: "Flag", Width: 60},
+		},
+		count:  func() int { return len(work.Triangles) },
+		cells:  work.triangleCells,
+		record: work.triangleRecord,
+		add:    work.addTriangle,
+		edit:   work.editTriangles,
+		delete: work.deleteTriangles,
+		paste:  work.pasteTriangles,
+	}
+	vertices.linked = triangles
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         "Mesh",
+		DefaultButton: &okPB,
+		CancelButton:  &cancelPB,
+		MinSize:       cpl.Size{Width: 750, Height: 500},
+		Layout:        cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.TabWidget{
+				Pages: []cpl.TabPage{
+					{Title: "Vertices", Layout: cpl.VBox{}, Children: []cpl.Widget{vertices.Widget()}},
+					{Title: "Triangles", Layout: cpl.VBox{}, Children: []cpl.Widget{triangles.Widget()}},
+				},
+			},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					cpl.PushButton{
+						AssignTo:  &okPB,
+						Text:      "OK",
+						OnClicked: func() { dlg.Accept() },
+					},
+				},
+			},
+		},
+	}
+	result, err := dia.Run(owner)
+	if err != nil {
+		return fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return fmt.Errorf("cancelled")
+	}
+	m.Vertices = work.Vertices
+	m.Triangles = work.Triangles
+	return nil
+}
+
+// meshGrid is a filterable table over a mesh collection. Rows are only
+// formatted when first needed and kept until the collection changes, so large
+// meshes stay responsive while filtering
+type meshGrid struct {
+	walk.TableModelBase
+	columns  []cpl.TableViewColumn
+	count    func() int
+	cells    func(idx int) []string
+	record   func(idx int) []string
+	add      func(owner walk.Form) error
+	edit     func(owner walk.Form, idxs []int) error
+	delete   func(idxs []int) error
+	paste    func(start int, records [][]string) error
+	linked   *meshGrid // grid whose cells depend on this collection
+	visible  []int
+	query    string
+	rows     [][]string // formatted cells by collection index, nil until needed
+	texts    []string   // lowercase row text for filtering, empty until needed
+	tv       *walk.TableView
+	leFilter *walk.LineEdit
+	lblCount *walk.Label
+}
+
+// Widget returns the declaration of the grid for a dialog
+func (g *meshGrid) Widget() cpl.Widget {
+	g.applyFilter()
+	return cpl.Composite{
+		Layout: cpl.VBox{MarginsZero: true},
+		Children: []cpl.Widget{
+			cpl.Composite{
+				Layout: cpl.HBox{MarginsZero: true},
+				Children: []cpl.Widget{
+					cpl.LineEdit{
+						AssignTo:      &g.leFilter,
+						CueBanner:     "Filter, or an index range like 10-20",
+						OnTextChanged: g.onFilter,
+					},
+					cpl.Label{AssignTo: &g.lblCount, Text: g.countText()},
+				},
+			},
+			cpl.TableView{
+				AssignTo:         &g.tv,
+				AlternatingRowBG: true,
+				MultiSelection:   true,
+				Columns:          g.columns,
+				Model:            g,
+				OnItemActivated:  g.onEdit,
+				OnKeyDown:        g.onKeyDown,
+			},
+			cpl.Composite{
+				Layout: cpl.HBox{MarginsZero: true},
+				Children: []cpl.Widget{
+					cpl.PushButton{Text: "Add", OnClicked: g.onAdd},
+					cpl.PushButton{Text: "Edit", OnClicked: g.onEdit},
+					cpl.PushButton{Text: "Delete", OnClicked: g.onDelete},
+					cpl.HSpacer{},
+					cpl.PushButton{Text: "Copy CSV", OnClicked: g.onCopy},
+					cpl.PushButton{Text: "Paste CSV", OnClicked: g.onPaste},
+				},
+			},
+		},
+	}
+}
+
+// RowCount is called by the TableView for the number of visible rows
+func (g *meshGrid) RowCount() int {
+	return len(g.visible)
+}
+
+// Value is called by the TableView for the text of a cell
+func (g *meshGrid) Value(row, col int) interface{} {
+	return g.row(g.visible[row])[col]
+}
+
+// row returns the cached cells of a collection index
+func (g *meshGrid) row(idx int) []string {
+	if len(g.rows) != g.count() {
+		g.rows = make([][]string, g.count())
+		g.texts = make([]string, g.count())
+	}
+	if g.rows[idx] == nil {
+		g.rows[idx] = g.cells(idx)
+	}
+	return g.rows[idx]
+}
+
+// text returns the cached lowercase text of a collection index for filtering
+func (g *meshGrid) text(idx int) string {
+	cells := g.row(idx)
+	if g.texts[idx] == "" {
+		g.texts[idx] = strings.ToLower(strings.Join(cells, " "))
+	}
+	return g.texts[idx]
+}
+
+func (g *meshGrid) onFilter() {
+	if g.tv == nil {
+		return
+	}
+	g.query = g.leFilter.Text()
+	g.refresh()
+}
+
+// applyFilter rebuilds the visible rows from the query
+func (g *meshGrid) applyFilter() {
+	query := strings.ToLower(strings.TrimSpace(g.query))
+	low, high, isRange := parseIndexRange(query)
+	g.visible = g.visible[:0]
+	for i := 0; i < g.count(); i++ {
+		switch {
+		case query == "":
+		case isRange:
+			if i < low || i > high {
+				continue
+			}
+		default:
+			if !strings.Contains(g.text(i), query) {
+				continue
+			}
+		}
+		g.visible = append(g.visible, i)
+	}
+}
+
+// reset drops the cached rows after the collection changes
+func (g *meshGrid) reset() {
+	g.rows = nil
+	g.texts = nil
+}
+
+func (g *meshGrid) refresh() {
+	g.applyFilter()
+	g.PublishRowsReset()
+	g.lblCount.SetText(g.countText())
+}
+
+func (g *meshGrid) countText() string {
+	if len(g.visible) == g.count() {
+		return fmt.Sprintf("%d rows", g.count())
+	}
+	return fmt.Sprintf("%d of %d rows", len(g.visible), g.count())
+}
+
+// selected returns the collection indexes of the selected rows
+func (g *meshGrid) selected() []int {
+	idxs := []int{}
+	for _, row := range g.tv.SelectedIndexes() {
+		if row >= 0 && row < len(g.visible) {
+			idxs = append(idxs, g.visible[row])
+		}
+	}
+	sort.Ints(idxs)
+	return idxs
+}
+
+func (g *meshGrid) onKeyDown(key walk.Key) {
+	if walk.ModifiersDown() != walk.ModControl {
+		return
+	}
+	switch key {
+	case walk.KeyC:
+		g.onCopy()
+	case walk.KeyV:
+		g.onPaste()
+	case walk.KeyA:
+		rows := []int{}
+		for i := range g.visible {
+			rows = append(rows, i)
+		}
+		g.tv.SetSelectedIndexes(rows)
+	}
+}
+
+func (g *meshGrid) onAdd() {
+	g.run("add", func() error { return g.add(g.tv.Form()) })
+}
+
+func (g *meshGrid) onEdit() {
+	idxs := g.selected()
+	if len(idxs) == 0 {
+		return
+	}
+	g.run("edit", func() error { return g.edit(g.tv.Form(), idxs) })
+}
+
+func (g *meshGrid) onDelete() {
+	idxs := g.selected()
+	if len(idxs) == 0 {
+		return
+	}
+	g.run("delete", func() error { return g.delete(idxs) })
+}
+
+func (g *meshGrid) onCopy() {
+	idxs := g.selected()
+	if len(idxs) == 0 {
+		return
+	}
+	buf := &strings.Builder{}
+	w := csv.NewWriter(buf)
+	for _, idx := range idxs {
+		w.Write(g.record(idx))
+	}
+	w.Flush()
+	err := walk.Clipboard().SetText(buf.String())
+	if err != nil {
+		popup.Errorf(g.tv.Form(), "copy: %s", err)
+	}
+}
+
+// onPaste overwrites rows from the first selected row, appending any past the end
+func (g *meshGrid) onPaste() {
+	text, err := walk.Clipboard().Text()
+	if err != nil {
+		popup.Errorf(g.tv.Form(), "paste: %s", err)
+		return
+	}
+	records, err := parseCSV(text)
+	if err != nil {
+		popup.Errorf(g.tv.Form(), "paste: %s", err)
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+	start := g.count()
+	idxs := g.selected()
+	if len(idxs) > 0 {
+		start = idxs[0]
+	}
+	g.run("paste", func() error { return g.paste(start, records) })
+}
+
+// run applies an action and refreshes the grid
+func (g *meshGrid) run(action string, fn func() error) {
+	err := fn()
+	g.reset()
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(g.tv.Form(), "%s: %s", action, err)
+		return
+	}
+	g.refresh()
+	if g.linked != nil {
+		g.linked.reset()
+		g.linked.refresh()
+	}
+}
+
+// parseIndexRange parses a query like 10-20
+func parseIndexRange(query string) (int, int, bool) {
+	lowText, highText, ok := strings.Cut(query, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	low, err := strconv.Atoi(strings.TrimSpace(lowText))
+	if err != nil {
+		return 0, 0, false
+	}
+	high, err := strconv.Atoi(strings.TrimSpace(highText))
+	if err != nil {
+		return 0, 0, false
+	}
+	return low, high, true
+}
+
+// parseCSV reads comma or tab separated records, skipping blank lines and a
+// header row. Only the first line can be a header, later text is left for the
+// caller to reject
+func parseCSV(text string) ([][]string, error) {
+	r := csv.NewReader(strings.NewReader(text))
+	if strings.Contains(text, "\t") {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	records := [][]string{}
+	isFirst := true
+	for _, line := range lines {
+		record := []string{}
+		for _, field := range line {
+			record = append(record, strings.TrimSpace(field))
+		}
+		if len(record) == 0 || (len(record) == 1 && record[0] == "") {
+			continue
+		}
+		if isFirst {
+			isFirst = false
+			_, err = strconv.ParseFloat(record[0], 64)
+			if err != nil {
+				continue
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// sharedText returns the text of the first of count items if every item matches, otherwise empty
+func sharedText(count int, format func(i int) string) string {
+	text := format(0)
+	for i := 1; i < count; i++ {
+		if format(i) != text {
+			return ""
+		}
+	}
+	return text
+}
+
+func formatRecord(values ...float32) []string {
+	record := []string{}
+	for _, v := range values {
+		record = append(record, strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	return record
+}
+
+func (m *modelData) vertexCells(idx int) []string {
+	vertex := m.Vertices[idx]
+	return []string{
+		strconv.Itoa(idx),
+		formatFloats(vertex.Position[:]...),
+		formatFloats(vertex.Normal[:]...),
+		formatFloats(vertex.Uv[:]...),
+		formatFloats(vertex.Uv2[:]...),
+		formatUint8s(vertex.Tint[:]...),
+	}
+}
+
+// vertexRecord is a vertex as csv: position, normal, uv, uv2 and color
+func (m *modelData) vertexRecord(idx int) []string {
+	vertex := m.Vertices[idx]
+	record := formatRecord(vertex.Position[:]...)
+	record = append(record, formatRecord(vertex.Normal[:]...)...)
+	record = append(record, formatRecord(vertex.Uv[:]...)...)
+	record = append(record, formatRecord(vertex.Uv2[:]...)...)
+	for _, v := range vertex.Tint {
+		record = append(record, strconv.Itoa(int(v)))
+	}
+	return record
+}
+
+func newVertex() *raw.Vertex {
+	return &raw.Vertex{Tint: [4]uint8{128, 128, 128, 255}}
+}
+
+func (m *modelData) addVertex(owner walk.Form) error {
+	vertex := newVertex()
+	err := showVertices(owner, "Add Vertex", []*raw.Vertex{vertex})
+	if err != nil {
+		return err
+	}
+	m.Vertices = append(m.Vertices, vertex)
+	return nil
+}
+
+func (m *modelData) editVertices(owner walk.Form, idxs []int) error {
+	vertices := []*raw.Vertex{}
+	for _, idx := range idxs {
+		vertices = append(vertices, m.Vertices[idx])
+	}
+	title := "Edit Vertex"
+	if len(idxs) > 1 {
+		title = fmt.Sprintf("Edit %d Vertices", len(idxs))
+	}
+	return showVertices(owner, title, vertices)
+}
+
+// deleteVertices removes unused vertices and shifts the triangle indices after them
+func (m *modelData) deleteVertices(idxs []int) error {
+	isDeleted := map[uint32]bool{}
+	for _, idx := range idxs {
+		isDeleted[uint32(idx)] = true
+	}
+	for i, triangle := range m.Triangles {
+		for _, index := range triangle.Index {
+			if int(index) >= len(m.Vertices) {
+				return fmt.Errorf("triangle %d uses vertex %d of %d", i, index, len(m.Vertices))
+			}
+			if isDeleted[index] {
+				return fmt.Errorf("vertex %d is used by triangle %d", index, i)
+			}
+		}
+	}
+
+	remap := make([]uint32, len(m.Vertices))
+	vertices := []*raw.Vertex{}
+	for i, vertex := range m.Vertices {
+		if isDeleted[uint32(i)] {
+			continue
+		}
+		remap[i] = uint32(len(vertices))
+		vertices = append(vertices, vertex)
+	}
+	m.Vertices = vertices
+	for i := range m.Triangles {
+		for j, index := range m.Triangles[i].Index {
+			m.Triangles[i].Index[j] = remap[index]
+		}
+	}
+	return nil
+}
+
+// pasteVertices overwrites vertices from start with records of 3 to 14 values,
+// in vertexRecord order. Missing values keep their current or default value
+func (m *modelData) pasteVertices(start int, records [][]string) error {
+	pasted := []*raw.Vertex{}
+	for i, record := range records {
+		if len(record) < 3 || len(record) > 14 {
+			return fmt.Errorf("line %d: expected 3 to 14 values, got %d", i+1, len(record))
+		}
+		vertex := newVertex()
+		if start+i < len(m.Vertices) {
+			*vertex = *m.Vertices[start+i]
+		}
+		floats := [][]float32{vertex.Position[:], vertex.Normal[:], vertex.Uv[:], vertex.Uv2[:]}
+		col := 0
+		for _, dst := range floats {
+			for j := range dst {
+				if col >= len(record) {
+					break
+				}
+				v, err := strconv.ParseFloat(record[col], 32)
+				if err != nil {
+					return fmt.Errorf("line %d: %s is not a number", i+1, record[col])
+				}
+				dst[j] = float32(v)
+				col++
+			}
+		}
+		for j := range vertex.Tint {
+			if col >= len(record) {
+				break
+			}
+			v, err := strconv.ParseUint(record[col], 10, 8)
+			if err != nil {
+				return fmt.Errorf("line %d: color %s is not between 0 and 255", i+1, record[col])
+			}
+			vertex.Tint[j] = uint8(v)
+			col++
+		}
+		pasted = append(pasted, vertex)
+	}
+
+	for i, vertex := range pasted {
+		if start+i < len(m.Vertices) {
+			m.Vertices[start+i] = vertex
+			continue
+		}
+		m.Vertices = append(m.Vertices, vertex)
+	}
+	return nil
+}
+
+// showVertices edits one or more vertices, fields left blank on a multi edit keep their values
+func showVertices(owner walk.Form, title string, vertices []*raw.Vertex) error {
+	apply := map[string]func(vertex *raw.Vertex){}
+	floatField := func(label string, get func(vertex *raw.Vertex) []float32) *formField {
+		text := sharedText(len(vertices), func(i int) string { return formatFloats(get(vertices[i])...) })
+		return &formField{Label: label, Text: text, Parse: func(value string) error {
+			delete(apply, label)
+			if value == "" && text == "" {
+				return nil
+			}
+			values, err := parseFloats(value, len(get(vertices[0])))
+			if err != nil {
+				return err
+			}
+			apply[label] = func(vertex *raw.Vertex) { copy(get(vertex), values) }
+			return nil
+		}}
+	}
+	colorText := sharedText(len(vertices), func(i int) string { return formatUint8s(vertices[i].Tint[:]...) })
+	fields := []*formField{
+		floatField("Position", func(vertex *raw.Vertex) []float32 { return vertex.Position[:] }),
+		floatField("Normal", func(vertex *raw.Vertex) []float32 { return vertex.Normal[:] }),
+		floatField("UV", func(vertex *raw.Vertex) []float32 { return vertex.Uv[:] }),
+		floatField("UV2", func(vertex *raw.Vertex) []float32 { return vertex.Uv2[:] }),
+		{Label: "Color", Text: colorText, Parse: func(value string) error {
+			delete(apply, "Color")
+			if value == "" && colorText == "" {
+				return nil
+			}
+			values, err := parseUint8s(value, 4)
+			if err != nil {
+				return err
+			}
+			apply["Color"] = func(vertex *raw.Vertex) { copy(vertex.Tint[:], values) }
+			return nil
+		}},
+	}
+	err := showFormEdit(owner, title, fields)
+	if err != nil {
+		return err
+	}
+	for _, vertex := range vertices {
+		for _, fn := range apply {
+			fn(vertex)
+		}
+	}
+	return nil
+}
+
+// materialIndex returns the index of the named material, or -1
+func (m *modelData) materialIndex(name string) int {
+	for i, material := range m.Materials {
+		if material.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *modelData) triangleCells(idx int) []string {
+	triangle := m.Triangles[idx]
+	materialIdx := "missing"
+	if i := m.materialIndex(triangle.MaterialName); i >= 0 {
+		materialIdx = strconv.Itoa(i)
+	}
+	return []string{
+		strconv.Itoa(idx),
+		fmt.Sprintf("%d, %d, %d", triangle.Index[0], triangle.Index[1], triangle.Index[2]),
+		triangle.MaterialName,
+		materialIdx,
+		strconv.Itoa(int(triangle.Flag)),
+	}
+}
+
+// triangleRecord is a triangle as csv: three vertex indices, material index and
+// flag. A material missing from the model is written as -1, which pasting reads
+// as keep the current material
+func (m *modelData) triangleRecord(idx int) []string {
+	triangle := m.Triangles[idx]
+	return []string{
+		strconv.Itoa(int(triangle.Index[0])),
+		strconv.Itoa(int(triangle.Index[1])),
+		strconv.Itoa(int(triangle.Index[2])),
+		strconv.Itoa(m.materialIndex(triangle.MaterialName)),
+		strconv.Itoa(int(triangle.Flag)),
+	}
+}
+
+func (m *modelData) addTriangle(owner walk.Form) error {
+	if len(m.Materials) == 0 {
+		return fmt.Errorf("add a material first")
+	}
+	triangle := raw.Triangle{MaterialName: m.Materials[0].Name}
+	err := m.showTriangles(owner, "Add Triangle", []*raw.Triangle{&triangle})
+	if err != nil {
+		return err
+	}
+	m.Triangles = append(m.Triangles, triangle)
+	return nil
+}
+
+func (m *modelData) editTriangles(owner walk.Form, idxs []int) error {
+	triangles := []*raw.Triangle{}
+	for _, idx := range idxs {
+		triangles = append(triangles, &m.Triangles[idx])
+	}
+	title := "Edit Triangle"
+	if len(idxs) > 1 {
+		title = fmt.Sprintf("Edit %d Triangles", len(idxs))
+	}
+	return m.showTriangles(owner, title, triangles)
+}
+
+func (m *modelData) deleteTriangles(idxs []int) error {
+	isDeleted := map[int]bool{}
+	for _, idx := range idxs {
+		isDeleted[idx] = true
+	}
+	triangles := []raw.Triangle{}
+	for i, triangle := range m.Triangles {
+		if !isDeleted[i] {
+			triangles = append(triangles, triangle)
+		}
+	}
+	m.Triangles = triangles
+	return nil
+}
+
+// pasteTriangles overwrites triangles from start with records of three vertex
+// indices, then optionally a material index and flag. A material index of -1
+// keeps the material of the triangle being overwritten
+func (m *modelData) pasteTriangles(start int, records [][]string) error {
+	pasted := []raw.Triangle{}
+	for i, record := range records {
+		if len(record) < 3 || len(record) > 5 {
+			return fmt.Errorf("line %d: expected 3 to 5 values, got %d", i+1, len(record))
+		}
+		triangle := raw.Triangle{}
+		if start+i < len(m.Triangles) {
+			triangle = m.Triangles[start+i]
+		} else if len(record) < 4 {
+			if len(m.Materials) == 0 {
+				return fmt.Errorf("line %d: material index is required, add a material first", i+1)
+			}
+			triangle.MaterialName = m.Materials[0].Name
+		}
+		for j := range triangle.Index {
+			v, err := parseIndex(record[j], 0, len(m.Vertices))
+			if err != nil {
+				return fmt.Errorf("line %d: vertex %w", i+1, err)
+			}
+			triangle.Index[j] = uint32(v)
+		}
+		if len(record) > 3 && record[3] == "-1" {
+			if start+i >= len(m.Triangles) {
+				return fmt.Errorf("line %d: material -1 only keeps the material of an existing triangle", i+1)
+			}
+		} else if len(record) > 3 {
+			v, err := parseIndex(record[3], 0, len(m.Materials))
+			if err != nil {
+				return fmt.Errorf("line %d: material %w", i+1, err)
+			}
+			triangle.MaterialName = m.Materials[v].Name
+		}
+		if len(record) > 4 {
+			v, err := parseUint32(record[4])
+			if err != nil {
+				return fmt.Errorf("line %d: flag %w", i+1, err)
+			}
+			triangle.Flag = v
+		}
+		pasted = append(pasted, triangle)
+	}
+
+	for i, triangle := range pasted {
+		if start+i < len(m.Triangles) {
+			m.Triangles[start+i] = triangle
+			continue
+		}
+		m.Triangles = append(m.Triangles, triangle)
+	}
+	return nil
+}
+
+// showTriangles edits one or more triangles, fields left blank on a multi edit keep their values
+func (m *modelData) showTriangles(owner walk.Form, title string, triangles []*raw.Triangle) error {
+	apply := map[string]func(triangle *raw.Triangle){}
+	indexText := sharedText(len(triangles), func(i int) string {
+		return fmt.Sprintf("%d, %d, %d", triangles[i].Index[0], triangles[i].Index[1], triangles[i].Index[2])
+	})
+	materialText := sharedText(len(triangles), func(i int) string {
+		return strconv.Itoa(m.materialIndex(triangles[i].MaterialName))
+	})
+	flagText := sharedText(len(triangles), func(i int) string { return strconv.Itoa(int(triangles[i].Flag)) })
+	fields := []*formField{
+		{Label: "Vertices", Text: indexText, Parse: func(value string) error {
+			delete(apply, "Vertices")
+			if value == "" && indexText == "" {
+				return nil
+			}
+			parts := strings.Split(value, ",")
+			if len(parts) != 3 {
+				return fmt.Errorf("expected 3 comma separated vertex indices")
+			}
+			index := [3]uint32{}
+			for i, part := range parts {
+				v, err := parseIndex(strings.TrimSpace(part), 0, len(m.Vertices))
+				if err != nil {
+					return err
+				}
+				index[i] = uint32(v)
+			}
+			apply["Vertices"] = func(triangle *raw.Triangle) { triangle.Index = index }
+			return nil
+		}},
+		{Label: "Material Index", Text: materialText, Parse: func(value string) error {
+			delete(apply, "Material Index")
+			// -1 is a material missing from the model, left as is
+			if (value == "" && materialText == "") || value == "-1" {
+				return nil
+			}
+			v, err := parseIndex(value, 0, len(m.Materials))
+			if err != nil {
+				return err
+			}
+			name := m.Materials[v].Name
+			apply["Material Index"] = func(triangle *raw.Triangle) { triangle.MaterialName = name }
+			return nil
+		}},
+		{Label: "Flag", Text: flagText, Parse: func(value string) error {
+			delete(apply, "Flag")
+			if value == "" && flagText == "" {
+				return nil
+			}
+			v, err := parseUint32(value)
+			if err != nil {
+				return err
+			}
+			apply["Flag"] = func(triangle *raw.Triangle) { triangle.Flag = v }
+			return nil
+		}},
+	}
+	err := showFormEdit(owner, title, fields)
+	if err != nil {
+		return err
+	}
+	for _, triangle := range triangles {
+		for _, fn := range apply {
+			fn(triangle)
+		}
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package dialog
 
 import (
 	"fmt"
+	"strconv"
 
+	"github.com/xackery/quail-gui/popup"
 	"github.com/xackery/quail/raw"
 	"github.com/xackery/wlk/cpl"
 	"github.com/xackery/wlk/walk"
@@ -15,100 +17,32 @@ func ShowModEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 		return fmt.Errorf("cast mod")
 	}
 
-	materials := []string{}
-	for _, material := range data.Materials {
-		materials = append(materials, material.Name)
-	}
-
-	defaultBone := ""
-	if len(data.Bones) > 0 {
-		defaultBone = data.Bones[0].Name
-	}
-	bones := []string{}
-	for _, bone := range data.Bones {
-		bones = append(bones, bone.Name)
-	}
-
-	defaultVertex := ""
-	if len(data.Vertices) > 0 {
-		defaultVertex = "1"
-	}
-	vertices := []string{}
-	for i := 0; i < len(data.Vertices); i++ {
-		vertices = append(vertices, fmt.Sprintf("%d", i+1))
-	}
-
-	defaultTriangle := ""
-	if len(data.Triangles) > 0 {
-		defaultTriangle = "1"
-	}
-	triangles := []string{}
-	for i := 0; i < len(data.Triangles); i++ {
-		triangles = append(triangles, fmt.Sprintf("%d", i+1))
-	}
+	model := newModelData(data.Materials, data.Bones, data.Vertices, data.Triangles)
 
+	var cmbVersion *walk.ComboBox
 	formElements := cpl.Composite{
 		Layout: cpl.VBox{},
 		Children: []cpl.Widget{
-			cpl.GenerateComposite(cpl.Grid{Columns: 2},
-				cpl.Label{Text: "Version:"},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    fmt.Sprintf("%d", data.Version),
-					Model:    []string{"1", "2", "3"},
-				},
-			),
-			cpl.Label{Text: "Materials:"},
-			cpl.ListBox{
-				Model: materials,
-				OnItemActivated: func() {
-					fmt.Println("item activated: ", data.Materials)
-				},
-			},
-			cpl.Label{Text: "Bones:"},
-			cpl.GenerateComposite(cpl.Grid{Columns: 3},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    defaultBone,
-					Model:    bones,
-				},
-				cpl.PushButton{
-					Text: "Add",
-				},
-				cpl.PushButton{
-					Text: "Edit",
-				},
-			),
-			cpl.Label{Text: "Vertices:"},
-			cpl.GenerateComposite(cpl.Grid{Columns: 3},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    defaultVertex,
-					Model:    vertices,
-				},
-				cpl.PushButton{
-					Text: "Add",
-				},
-				cpl.PushButton{
-					Text: "Edit",
-				},
-			),
-			cpl.Label{Text: "Triangles:"},
-			cpl.GenerateComposite(cpl.Grid{Columns: 3},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    defaultTriangle,
-					Model:    triangles,
-				},
-				cpl.PushButton{
-					Text: "Add",
-				},
-				cpl.PushButton{
-					Text: "Edit",
-				},
-			),
+			versionWidget(&cmbVersion, data.Version),
 		},
 	}
+	for _, editor := range model.editors() {
+		formElements.Children = append(formElements.Children, editor.Widget())
+	}
+	formElements.Children = append(formElements.Children, model.meshWidget())
+
+	onSave := func() error {
+		newVersion, err := strconv.Atoi(cmbVersion.Text())
+		if err != nil {
+			return fmt.Errorf("parse version: %w", err)
+		}
+		data.Version = uint32(newVersion)
+		data.Materials = model.Materials
+		data.Bones = model.Bones
+		data.Vertices = model.Vertices
+		data.Triangles = model.Triangles
+		return nil
+	}
 
 	var dlg *walk.Dialog
 	dia := cpl.Dialog{
@@ -124,16 +58,23 @@ func ShowModEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 				Layout: cpl.HBox{},
 				Children: []cpl.Widget{
 					cpl.HSpacer{},
-					cpl.PushButton{
-						AssignTo:  &savePB,
-						Text:      "Save",
-						OnClicked: func() { dlg.Accept() },
-					},
 					cpl.PushButton{
 						AssignTo:  &cancelPB,
 						Text:      "Cancel",
 						OnClicked: func() { dlg.Cancel() },
 					},
+					cpl.PushButton{
+						AssignTo: &savePB,
+						Text:     "Save",
+						OnClicked: func() {
+							err := onSave()
+							if err != nil {
+								popup.Errorf(dlg, "save: %s", err.Error())
+								return
+							}
+							dlg.Accept()
+						},
+					},
 				},
 			},
 		},
@@ -0,0 +1,363 @@
+package dialog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xackery/quail/raw"
+	"github.com/xackery/wlk/walk"
+)
+
+// modelData is an editable copy of the collections shared by mod and mds,
+// so cancelling a dialog leaves the source untouched
+type modelData struct {
+	Materials []*raw.Material
+	Bones     []*raw.Bone
+	Vertices  []*raw.Vertex
+	Triangles []raw.Triangle
+}
+
+func newModelData(materials []*raw.Material, bones []*raw.Bone, vertices []*raw.Vertex, triangles []raw.Triangle) *modelData {
+	m := &modelData{}
+	for _, src := range materials {
+		material := *src
+		material.Properties = []*raw.MaterialParam{}
+		for _, prop := range src.Properties {
+			p := *prop
+			material.Properties = append(material.Properties, &p)
+		}
+		m.Materials = append(m.Materials, &material)
+	}
+	for _, src := range bones {
+		bone := *src
+		m.Bones = append(m.Bones, &bone)
+	}
+	for _, src := range vertices {
+		vertex := *src
+		m.Vertices = append(m.Vertices, &vertex)
+	}
+	m.Triangles = append(m.Triangles, triangles...)
+	return m
+}
+
+// editors returns a collection editor for materials and bones, see meshWidget for geometry
+func (m *modelData) editors() []*collectionEditor {
+	return []*collectionEditor{
+		{Title: "Materials", Items: m.materialItems, Add: m.addMaterial, Edit: m.editMaterial, Delete: m.deleteMaterial},
+		{Title: "Bones", Items: m.boneItems, Add: m.addBone, Edit: m.editBone, Delete: m.deleteBone},
+	}
+}
+
+func (m *modelData) materialItems() []string {
+	items := []string{}
+	for _, material := range m.Materials {
+		items = append(items, fmt.Sprintf("%s (%s)", material.Name, material.ShaderName))
+	}
+	return items
+}
+
+func (m *modelData) addMaterial(owner walk.Form) error {
+	material := &raw.Material{ID: int32(len(m.Materials)), Properties: []*raw.MaterialParam{}}
+	err := m.showMaterial(owner, "Add Material", material, -1)
+	if err != nil {
+		return err
+	}
+	m.Materials = append(m.Materials, material)
+	return nil
+}
+
+func (m *modelData) editMaterial(owner walk.Form, idx int) error {
+	material := m.Materials[idx]
+	oldName := material.Name
+	edit := newModelData([]*raw.Material{material}, nil, nil, nil).Materials[0]
+	err := m.showMaterial(owner, "Edit Material", edit, idx)
+	if err != nil {
+		return err
+	}
+	m.Materials[idx] = edit
+	if edit.Name == oldName {
+		return nil
+	}
+	for i := range m.Triangles {
+		if m.Triangles[i].MaterialName == oldName {
+			m.Triangles[i].MaterialName = edit.Name
+		}
+	}
+	return nil
+}
+
+func (m *modelData) deleteMaterial(owner walk.Form, idx int) error {
+	name := m.Materials[idx].Name
+	for i, triangle := range m.Triangles {
+		if triangle.MaterialName == name {
+			return fmt.Errorf("%s is used by triangle %d", name, i)
+		}
+	}
+	m.Materials = append(m.Materials[:idx], m.Materials[idx+1:]...)
+	return nil
+}
+
+// showMaterial edits material in place, skip is the index of the material being edited.
+// Property edits apply immediately, so callers pass a copy when editing
+func (m *modelData) showMaterial(owner walk.Form, title string, material *raw.Material, skip int) error {
+	id, name, shader, flag := material.ID, material.Name, material.ShaderName, material.Flag
+	fields := []*formField{
+		{Label: "ID", Text: strconv.Itoa(int(id)), Parse: func(text string) error {
+			v, err := strconv.ParseInt(text, 10, 32)
+			if err != nil {
+				return fmt.Errorf("%s is not a whole number", text)
+			}
+			id = int32(v)
+			return nil
+		}},
+		{Label: "Name", Text: name, Parse: func(text string) error {
+			if text == "" {
+				return fmt.Errorf("required")
+			}
+			for i, other := range m.Materials {
+				if i != skip && strings.EqualFold(other.Name, text) {
+					return fmt.Errorf("%s already exists", text)
+				}
+			}
+			name = text
+			return nil
+		}},
+		{Label: "Shader", Text: shader, Parse: func(text string) error {
+			shader = text
+			return nil
+		}},
+		{Label: "Flag", Text: strconv.Itoa(int(flag)), Parse: func(text string) (err error) {
+			flag, err = parseUint32(text)
+			return err
+		}},
+	}
+
+	props := &collectionEditor{
+		Title: "Properties",
+		Items: func() []string {
+			items := []string{}
+			for _, prop := range material.Properties {
+				items = append(items, fmt.Sprintf("%s = %s", prop.Name, prop.Value))
+			}
+			return items
+		},
+		Add: func(owner walk.Form) error {
+			prop := &raw.MaterialParam{}
+			err := showMaterialParam(owner, "Add Property", material, prop, -1)
+			if err != nil {
+				return err
+			}
+			material.Properties = append(material.Properties, prop)
+			return nil
+		},
+		Edit: func(owner walk.Form, idx int) error {
+			return showMaterialParam(owner, "Edit Property", material, material.Properties[idx], idx)
+		},
+		Delete: func(owner walk.Form, idx int) error {
+			material.Properties = append(material.Properties[:idx], material.Properties[idx+1:]...)
+			return nil
+		},
+	}
+
+	err := showFormEdit(owner, title, fields, props.Widget())
+	if err != nil {
+		return err
+	}
+	material.ID, material.Name, material.ShaderName, material.Flag = id, name, shader, flag
+	return nil
+}
+
+func showMaterialParam(owner walk.Form, title string, material *raw.Material, prop *raw.MaterialParam, skip int) error {
+	name, category, value := prop.Name, prop.Category, prop.Value
+	fields := []*formField{
+		{Label: "Name", Text: name, Parse: func(text string) error {
+			if text == "" {
+				return fmt.Errorf("required")
+			}
+			for i, other := range material.Properties {
+				if i != skip && strings.EqualFold(other.Name, text) {
+					return fmt.Errorf("%s already exists", text)
+				}
+			}
+			name = text
+			return nil
+		}},
+		{Label: "Category", Text: strconv.Itoa(int(category)), Parse: func(text string) (err error) {
+			category, err = parseUint32(text)
+			return err
+		}},
+		{Label: "Value", Text: value, Parse: func(text string) error {
+			value = text
+			return nil
+		}},
+	}
+	err := showFormEdit(owner, title, fields)
+	if err != nil {
+		return err
+	}
+	prop.Name, prop.Category, prop.Value = name, category, value
+	return nil
+}
+
+func (m *modelData) boneItems() []string {
+	items := []string{}
+	for i, bone := range m.Bones {
+		items = append(items, fmt.Sprintf("%d: %s", i, bone.Name))
+	}
+	return items
+}
+
+func (m *modelData) addBone(owner walk.Form) error {
+	bone := &raw.Bone{Next: -1, ChildIndex: -1, Rotation: [4]float32{0, 0, 0, 1}, Scale: [3]float32{1, 1, 1}}
+	parent := -1
+	if len(m.Bones) > 0 {
+		parent = 0
+	}
+	err := m.showBone(owner, "Add Bone", bone, -1, &parent)
+	if err != nil {
+		return err
+	}
+	m.Bones = append(m.Bones, bone)
+	setBoneParent(m.Bones, len(m.Bones)-1, parent)
+	return nil
+}
+
+func (m *modelData) editBone(owner walk.Form, idx int) error {
+	parent := boneParent(m.Bones, idx)
+	err := m.showBone(owner, "Edit Bone", m.Bones[idx], idx, &parent)
+	if err != nil {
+		return err
+	}
+	if parent != boneParent(m.Bones, idx) {
+		setBoneParent(m.Bones, idx, parent)
+	}
+	return nil
+}
+
+// deleteBone removes a bone without children and fixes up the indices of bones after it
+func (m *modelData) deleteBone(owner walk.Form, idx int) error {
+	if m.Bones[idx].ChildIndex != -1 {
+		return fmt.Errorf("%s has children, delete or move them first", m.Bones[idx].Name)
+	}
+	setBoneParent(m.Bones, idx, -1)
+	m.Bones = append(m.Bones[:idx], m.Bones[idx+1:]...)
+	fix := func(ref int32) int32 {
+		if ref > int32(idx) {
+			return ref - 1
+		}
+		return ref
+	}
+	for _, bone := range m.Bones {
+		bone.Next = fix(bone.Next)
+		bone.ChildIndex = fix(bone.ChildIndex)
+	}
+	return nil
+}
+
+// showBone edits bone and its parent in place, skip is the index of the bone being edited
+func (m *modelData) showBone(owner walk.Form, title string, bone *raw.Bone, skip int, parent *int) error {
+	edit := *bone
+	newParent := *parent
+	fields := []*formField{
+		{Label: "Name", Text: edit.Name, Parse: func(text string) error {
+			if text == "" {
+				return fmt.Errorf("required")
+			}
+			for i, other := range m.Bones {
+				if i != skip && strings.EqualFold(other.Name, text) {
+					return fmt.Errorf("%s already exists", text)
+				}
+			}
+			edit.Name = text
+			return nil
+		}},
+		{Label: "Parent", Text: strconv.Itoa(newParent), Parse: func(text string) error {
+			v, err := parseIndex(text, -1, len(m.Bones))
+			if err != nil {
+				return err
+			}
+			if skip >= 0 && isBoneDescendant(m.Bones, v, skip) {
+				return fmt.Errorf("%d is %s or one of its children", v, edit.Name)
+			}
+			newParent = v
+			return nil
+		}},
+		{Label: "Pivot", Text: formatFloats(edit.Pivot[:]...), Parse: func(text string) error {
+			return parseFloatsInto(text, edit.Pivot[:])
+		}},
+		{Label: "Rotation", Text: formatFloats(edit.Rotation[:]...), Parse: func(text string) error {
+			return parseFloatsInto(text, edit.Rotation[:])
+		}},
+		{Label: "Scale", Text: formatFloats(edit.Scale[:]...), Parse: func(text string) error {
+			return parseFloatsInto(text, edit.Scale[:])
+		}},
+	}
+	err := showFormEdit(owner, title, fields)
+	if err != nil {
+		return err
+	}
+	*bone = edit
+	*parent = newParent
+	return nil
+}
+
+// boneParent returns the bone whose child list contains idx, or -1 for a root bone.
+// Bones link to their first child with ChildIndex and to their next sibling with Next
+func boneParent(bones []*raw.Bone, idx int) int {
+	for i, bone := range bones {
+		child := bone.ChildIndex
+		for steps := 0; child >= 0 && int(child) < len(bones) && steps < len(bones); steps++ {
+			if int(child) == idx {
+				return i
+			}
+			child = bones[child].Next
+		}
+	}
+	return -1
+}
+
+// isBoneDescendant reports if idx is ancestor or one of its descendants
+func isBoneDescendant(bones []*raw.Bone, idx int, ancestor int) bool {
+	for steps := 0; idx >= 0 && steps <= len(bones); steps++ {
+		if idx == ancestor {
+			return true
+		}
+		idx = boneParent(bones, idx)
+	}
+	return false
+}
+
+// setBoneParent unlinks idx from its current parent and appends it to parent's children
+func setBoneParent(bones []*raw.Bone, idx int, parent int) {
+	bone := bones[idx]
+	old := boneParent(bones, idx)
+	if old >= 0 && int(bones[old].ChildIndex) == idx {
+		bones[old].ChildIndex = bone.Next
+	} else {
+		for _, other := range bones {
+			if int(other.Next) == idx {
+				other.Next = bone.Next
+				break
+			}
+		}
+	}
+	if old >= 0 && bones[old].ChildrenCount > 0 {
+		bones[old].ChildrenCount--
+	}
+	bone.Next = -1
+	if parent < 0 {
+		return
+	}
+
+	bones[parent].ChildrenCount++
+	if bones[parent].ChildIndex < 0 {
+		bones[parent].ChildIndex = int32(idx)
+		return
+	}
+	last := bones[bones[parent].ChildIndex]
+	for steps := 0; last.Next >= 0 && int(last.Next) < len(bones) && steps < len(bones); steps++ {
+		last = bones[last.Next]
+	}
+	last.Next = int32(idx)
+}
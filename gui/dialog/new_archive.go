@@ -0,0 +1,69 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// ShowNewArchive lets the user pick a template for a new archive, returning its index
+func ShowNewArchive(mw *walk.MainWindow, templates []string) (int, error) {
+	var createPB, cancelPB *walk.PushButton
+
+	var lbTemplate *walk.ListBox
+	var dlg *walk.Dialog
+	selected := -1
+	onCreate := func() {
+		idx := lbTemplate.CurrentIndex()
+		if idx < 0 || idx >= len(templates) {
+			popup.Errorf(dlg, "new archive: select a template")
+			return
+		}
+		selected = idx
+		dlg.Accept()
+	}
+
+	dia := cpl.Dialog{
+		AssignTo:      &dlg,
+		Title:         "New Archive",
+		DefaultButton: &createPB,
+		CancelButton:  &cancelPB,
+		MinSize:       cpl.Size{Width: 300, Height: 250},
+		Layout:        cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Label{Text: "Template:"},
+			cpl.ListBox{
+				AssignTo:        &lbTemplate,
+				Model:           templates,
+				CurrentIndex:    0,
+				OnItemActivated: onCreate,
+			},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					cpl.PushButton{
+						AssignTo:  &createPB,
+						Text:      "Create",
+						OnClicked: onCreate,
+					},
+				},
+			},
+		},
+	}
+	result, err := dia.Run(mw)
+	if err != nil {
+		return -1, fmt.Errorf("run dialog: %w", err)
+	}
+	if result != walk.DlgCmdOK {
+		return -1, fmt.Errorf("cancelled")
+	}
+	return selected, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/xackery/quail-gui/config"
 	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/session"
 	"github.com/xackery/quail-gui/slog"
 	"github.com/xackery/wlk/cpl"
 	"github.com/xackery/wlk/walk"
@@ -34,10 +35,29 @@ func ShowPreferences(mw *walk.MainWindow) error {
 		},
 	})
 
+	var neBackupCount *walk.NumberEdit
+	formElements.Children = append(formElements.Children, cpl.GroupBox{
+		Title:  "Save Options",
+		Layout: cpl.Grid{Columns: 2},
+		Children: []cpl.Widget{
+			cpl.Label{Text: "Backups to keep:", ToolTipText: "How many timestamped .bak copies are kept next to each archive, 0 disables backups"},
+			cpl.NumberEdit{
+				AssignTo:    &neBackupCount,
+				Value:       float64(cfg.BackupCount),
+				Decimals:    0,
+				MinValue:    0,
+				MaxValue:    100,
+				ToolTipText: "How many timestamped .bak copies are kept next to each archive, 0 disables backups",
+			},
+		},
+	})
+
 	var dlg *walk.Dialog
 	onSave := func() {
 		cfg := config.Instance()
 		cfg.IsVirtualWld = chkIsVirtualWld.Checked()
+		cfg.BackupCount = int(neBackupCount.Value())
+		session.SetBackupCount(cfg.BackupCount)
 		err := cfg.Save()
 		if err != nil {
 			popup.Errorf(mw, "save config: %s", err)
@@ -0,0 +1,89 @@
+package dialog
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// ShowProgress runs work in the background behind a modal progress bar. work is
+// passed a progress func that updates the bar and returns an error once the user
+// has cancelled
+func ShowProgress(mw *walk.MainWindow, title string, work func(progress func(done int, total int, text string) error) error) error {
+	var cancelPB *walk.PushButton
+	var pbProgress *walk.ProgressBar
+	var lblProgress *walk.Label
+
+	var dlg *walk.Dialog
+	isCancelled := atomic.Bool{}
+	var workErr error
+
+	dia := cpl.Dialog{
+		AssignTo:     &dlg,
+		Title:        title,
+		CancelButton: &cancelPB,
+		MinSize:      cpl.Size{Width: 400, Height: 100},
+		Layout:       cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Label{AssignTo: &lblProgress, Text: "Starting..."},
+			cpl.ProgressBar{AssignTo: &pbProgress},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo: &cancelPB,
+						Text:     "Cancel",
+						OnClicked: func() {
+							isCancelled.Store(true)
+							cancelPB.SetEnabled(false)
+						},
+					},
+				},
+			},
+		},
+	}
+	err := dia.Create(mw)
+	if err != nil {
+		return fmt.Errorf("create dialog: %w", err)
+	}
+
+	progress := func(done int, total int, text string) error {
+		dlg.Synchronize(func() {
+			pbProgress.SetRange(0, total)
+			pbProgress.SetValue(done)
+			lblProgress.SetText(fmt.Sprintf("%d/%d %s", done, total, text))
+		})
+		if isCancelled.Load() {
+			return fmt.Errorf("cancelled")
+		}
+		return nil
+	}
+
+	isDone := false
+	dlg.Closing().Attach(func(canceled *bool, reason byte) {
+		if isDone {
+			return
+		}
+		// keep the dialog up until work has noticed the cancel
+		*canceled = true
+		isCancelled.Store(true)
+		cancelPB.SetEnabled(false)
+	})
+
+	dlg.Starting().Attach(func() {
+		go func() {
+			err := work(progress)
+			dlg.Synchronize(func() {
+				workErr = err
+				isDone = true
+				dlg.Accept()
+			})
+		}()
+	})
+
+	dlg.Run()
+	return workErr
+}
@@ -0,0 +1,98 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/xackery/quail-gui/gui/component"
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// showTextEdit edits text in a component.TextEditor, returning the new text.
+// Closing without changes returns a cancelled error. When validate is set, text
+// with problems is only saved if the user overrides them
+func showTextEdit(mw *walk.MainWindow, title string, text string, validate func(text string) []component.TextProblem) (string, error) {
+	var savePB, cancelPB *walk.PushButton
+	var dlg *walk.Dialog
+
+	editor := component.NewTextEditor(text)
+	newText := text
+	onValidate := func() bool {
+		if validate == nil {
+			return true
+		}
+		problems := validate(editor.Text())
+		editor.SetProblems(problems)
+		return len(problems) == 0
+	}
+	isSaved := false
+	onSave := func() {
+		newText = editor.Text()
+		if newText == text {
+			dlg.Cancel()
+			return
+		}
+		if !onValidate() && !popup.MessageBoxYesNo(dlg, "Invalid Text", "The text has problems and may not load. Save anyway?") {
+			return
+		}
+		isSaved = true
+		dlg.Accept()
+	}
+	editor.OnSave = onSave
+
+	dia := cpl.Dialog{
+		AssignTo:     &dlg,
+		Title:        title,
+		CancelButton: &cancelPB,
+		MinSize:      cpl.Size{Width: 600, Height: 400},
+		Layout:       cpl.VBox{},
+		Children: []cpl.Widget{
+			editor.Widget(),
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.PushButton{
+						Text:    "&Validate",
+						Visible: validate != nil,
+						OnClicked: func() {
+							if onValidate() {
+								popup.MessageBox(dlg, "Validate", "No problems found", false)
+							}
+						},
+					},
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "&Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					cpl.PushButton{
+						AssignTo:  &savePB,
+						Text:      "&Save",
+						OnClicked: onSave,
+					},
+				},
+			},
+		},
+	}
+	err := dia.Create(mw)
+	if err != nil {
+		return "", fmt.Errorf("create dialog: %w", err)
+	}
+	// cancel, escape and the close box all land here, ask before dropping edits
+	dlg.Closing().Attach(func(canceled *bool, reason byte) {
+		if isSaved || editor.Text() == text {
+			return
+		}
+		if !popup.MessageBoxYesNo(dlg, "Discard Changes", "Discard your changes to "+title+"?") {
+			*canceled = true
+		}
+	})
+
+	result := dlg.Run()
+	if result != walk.DlgCmdOK {
+		return "", fmt.Errorf("cancelled")
+	}
+	return newText, nil
+}
@@ -0,0 +1,161 @@
+package dialog
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/texture"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+var textureZooms = []float64{0.25, 0.5, 1, 2, 4, 8}
+
+// ShowTextureView shows a texture at full resolution with zoom, channel and mip level controls
+func ShowTextureView(mw *walk.MainWindow, title string, data []byte) error {
+	info, err := texture.Inspect(data)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", title, err)
+	}
+
+	zooms := []string{}
+	for _, zoom := range textureZooms {
+		zooms = append(zooms, fmt.Sprintf("%g%%", zoom*100))
+	}
+	mips := []string{}
+	for i := 0; i < info.MipCount; i++ {
+		mips = append(mips, fmt.Sprintf("%d (%dx%d)", i, max(1, info.Width>>i), max(1, info.Height>>i)))
+	}
+
+	var closePB *walk.PushButton
+	var cbZoom, cbMip *walk.ComboBox
+	var chkRed, chkGreen, chkBlue, chkAlpha *walk.CheckBox
+	var ivTexture *walk.ImageView
+	var lblInfo *walk.Label
+	var bitmap *walk.Bitmap
+	var mip image.Image
+	var infoText string
+	var dlg *walk.Dialog
+
+	onRender := func() {
+		if ivTexture == nil || chkAlpha == nil || lblInfo == nil {
+			// widgets are still being created
+			return
+		}
+		if mip == nil {
+			return
+		}
+		zoom := 1.0
+		if cbZoom.CurrentIndex() >= 0 {
+			zoom = textureZooms[cbZoom.CurrentIndex()]
+		}
+		text := infoText
+		if maxZoom := max(1, texture.MaxZoom(mip)); zoom > maxZoom {
+			zoom = maxZoom
+			text += fmt.Sprintf("   Zoom limited to %.0f%%", zoom*100)
+		}
+		lblInfo.SetText(text)
+		img := texture.Filter(mip, texture.Channels{
+			Red:   chkRed.Checked(),
+			Green: chkGreen.Checked(),
+			Blue:  chkBlue.Checked(),
+			Alpha: chkAlpha.Checked(),
+		})
+		newBitmap, err := walk.NewBitmapFromImageForDPI(texture.Scale(img, zoom), 96)
+		if err != nil {
+			popup.Errorf(dlg, "render: %s", err)
+			return
+		}
+		err = ivTexture.SetImage(newBitmap)
+		if err != nil {
+			popup.Errorf(dlg, "render: %s", err)
+		}
+		if bitmap != nil {
+			bitmap.Dispose()
+		}
+		bitmap = newBitmap
+	}
+
+	onMip := func() {
+		if cbMip == nil || lblInfo == nil {
+			return
+		}
+		level := max(0, cbMip.CurrentIndex())
+		img, err := texture.DecodeMip(data, level)
+		if err != nil {
+			popup.Errorf(dlg, "mip %d: %s", level, err)
+			return
+		}
+		mip = img
+		infoText = fmt.Sprintf("Format: %s   Size: %dx%d   Mips: %d   Compression: %s   Showing: %dx%d",
+			info.Format, info.Width, info.Height, info.MipCount, info.Compression, img.Bounds().Dx(), img.Bounds().Dy())
+		onRender()
+	}
+
+	dia := cpl.Dialog{
+		AssignTo:     &dlg,
+		Title:        fmt.Sprintf("%s (Texture)", title),
+		CancelButton: &closePB,
+		MinSize:      cpl.Size{Width: 500, Height: 400},
+		Layout:       cpl.VBox{},
+		Children: []cpl.Widget{
+			cpl.Composite{
+				Layout: cpl.HBox{MarginsZero: true},
+				Children: []cpl.Widget{
+					cpl.Label{Text: "Zoom:"},
+					cpl.ComboBox{
+						AssignTo:              &cbZoom,
+						Model:                 zooms,
+						CurrentIndex:          2,
+						OnCurrentIndexChanged: onRender,
+					},
+					cpl.Label{Text: "Mip:"},
+					cpl.ComboBox{
+						AssignTo:              &cbMip,
+						Model:                 mips,
+						CurrentIndex:          0,
+						Enabled:               info.MipCount > 1,
+						OnCurrentIndexChanged: onMip,
+					},
+					cpl.CheckBox{AssignTo: &chkRed, Text: "R", Checked: true, OnCheckedChanged: onRender},
+					cpl.CheckBox{AssignTo: &chkGreen, Text: "G", Checked: true, OnCheckedChanged: onRender},
+					cpl.CheckBox{AssignTo: &chkBlue, Text: "B", Checked: true, OnCheckedChanged: onRender},
+					cpl.CheckBox{AssignTo: &chkAlpha, Text: "A", Checked: true, OnCheckedChanged: onRender},
+					cpl.HSpacer{},
+				},
+			},
+			cpl.ScrollView{
+				Layout: cpl.VBox{},
+				Children: []cpl.Widget{
+					cpl.ImageView{
+						AssignTo: &ivTexture,
+						Mode:     cpl.ImageViewModeIdeal,
+					},
+				},
+			},
+			cpl.Label{AssignTo: &lblInfo},
+			cpl.Composite{
+				Layout: cpl.HBox{},
+				Children: []cpl.Widget{
+					cpl.HSpacer{},
+					cpl.PushButton{
+						AssignTo:  &closePB,
+						Text:      "&Close",
+						OnClicked: func() { dlg.Cancel() },
+					},
+				},
+			},
+		},
+	}
+	err = dia.Create(mw)
+	if err != nil {
+		return fmt.Errorf("create dialog: %w", err)
+	}
+	onMip()
+	dlg.Run()
+	if bitmap != nil {
+		bitmap.Dispose()
+	}
+	return nil
+}
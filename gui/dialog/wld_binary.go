@@ -1,8 +1,16 @@
 package dialog
 
 import (
+	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/wldfrag"
 	"github.com/xackery/quail/raw"
 	"github.com/xackery/wlk/cpl"
 	"github.com/xackery/wlk/walk"
@@ -15,45 +23,16 @@ func ShowWldEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 		return fmt.Errorf("cast wld")
 	}
 
-	defaultFragment := ""
-	if len(data.Fragments) > 0 {
-		defaultFragment = fmt.Sprintf("%d: %s", 1, raw.FragName(data.Fragments[1].FragCode()))
-	}
-	fragments := []string{}
-	for i := 1; i < len(data.Fragments); i++ {
-		fragment, ok := data.Fragments[i]
-		if !ok {
-			continue
-		}
-		fragments = append(fragments, fmt.Sprintf("%d: %s", i, raw.FragName(fragment.FragCode())))
-	}
+	browser := newFragmentBrowser(data)
 
-	formElements := cpl.Composite{
-		Layout: cpl.VBox{},
-		Children: []cpl.Widget{
-			cpl.GenerateComposite(cpl.Grid{Columns: 2},
-				cpl.Label{Text: "Version:"},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    fmt.Sprintf("%d", data.Version),
-					Model:    []string{"1", "2", "3"},
-				},
-			),
-			cpl.Label{Text: "Fragments:"},
-			cpl.GenerateComposite(cpl.Grid{Columns: 3},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    defaultFragment,
-					Model:    fragments,
-				},
-				cpl.PushButton{
-					Text: "Add",
-				},
-				cpl.PushButton{
-					Text: "Edit",
-				},
-			),
-		},
+	var cmbVersion *walk.ComboBox
+	onSave := func() error {
+		newVersion, err := strconv.Atoi(cmbVersion.Text())
+		if err != nil {
+			return fmt.Errorf("parse version: %w", err)
+		}
+		data.Version = uint32(newVersion)
+		return nil
 	}
 
 	var dlg *walk.Dialog
@@ -62,35 +41,341 @@ func ShowWldEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 		Title:         data.FileName(),
 		DefaultButton: &savePB,
 		CancelButton:  &cancelPB,
-		MinSize:       cpl.Size{Width: 300, Height: 300},
+		MinSize:       cpl.Size{Width: 900, Height: 600},
 		Layout:        cpl.VBox{},
 		Children: []cpl.Widget{
-			formElements,
+			versionWidget(&cmbVersion, data.Version),
+			browser.Widget(),
 			cpl.Composite{
 				Layout: cpl.HBox{},
 				Children: []cpl.Widget{
 					cpl.HSpacer{},
-					cpl.PushButton{
-						AssignTo:  &savePB,
-						Text:      "Save",
-						OnClicked: func() { dlg.Accept() },
-					},
 					cpl.PushButton{
 						AssignTo:  &cancelPB,
 						Text:      "Cancel",
 						OnClicked: func() { dlg.Cancel() },
 					},
+					cpl.PushButton{
+						AssignTo: &savePB,
+						Text:     "Save",
+						OnClicked: func() {
+							err := onSave()
+							if err != nil {
+								popup.Errorf(dlg, "save: %s", err.Error())
+								return
+							}
+							dlg.Accept()
+						},
+					},
 				},
 			},
 		},
 	}
-	result, err := dia.Run(mw)
+	err := dia.Create(mw)
 	if err != nil {
-		return fmt.Errorf("run dialog: %w", err)
+		return fmt.Errorf("create dialog: %w", err)
 	}
+	browser.onTypeChanged()
+
+	result := dlg.Run()
 	if result != walk.DlgCmdOK {
 		return fmt.Errorf("cancelled")
 	}
 
 	return nil
 }
+
+// fragmentBrowser lists the fragments of a wld by type, showing the decoded
+// fields, references both ways and bytes of the selected one
+type fragmentBrowser struct {
+	data      *raw.Wld
+	graph     *wldfrag.Graph
+	indexes   []int    // every fragment index, ascending
+	types     []string // fragment names for the type filter, "" is all
+	counts    map[string]int
+	visible   []int
+	refs      []wldfrag.Ref
+	users     []int
+	cmbType   *walk.ComboBox
+	lbFrags   *walk.ListBox
+	tvFields  *walk.TableView
+	fields    *rowsModel
+	lbRefs    *walk.ListBox
+	lbUsers   *walk.ListBox
+	teHex     *walk.TextEdit
+	isLoading bool
+}
+
+func newFragmentBrowser(data *raw.Wld) *fragmentBrowser {
+	b := &fragmentBrowser{
+		data:   data,
+		graph:  wldfrag.NewGraph(data.Fragments),
+		fields: &rowsModel{},
+		counts: map[string]int{},
+	}
+	for idx, frag := range data.Fragments {
+		if frag == nil {
+			continue
+		}
+		b.indexes = append(b.indexes, idx)
+		b.counts[b.typeName(idx)]++
+	}
+	sort.Ints(b.indexes)
+
+	b.types = []string{""}
+	for name := range b.counts {
+		b.types = append(b.types, name)
+	}
+	sort.Strings(b.types[1:])
+	return b
+}
+
+// Widget returns the declaration of the browser for a dialog
+func (b *fragmentBrowser) Widget() cpl.Widget {
+	typeLabels := []string{fmt.Sprintf("All (%d)", len(b.indexes))}
+	for _, name := range b.types[1:] {
+		typeLabels = append(typeLabels, fmt.Sprintf("%s (%d)", name, b.counts[name]))
+	}
+
+	return cpl.HSplitter{
+		Children: []cpl.Widget{
+			cpl.Composite{
+				Layout:        cpl.VBox{MarginsZero: true},
+				StretchFactor: 1,
+				Children: []cpl.Widget{
+					cpl.ComboBox{
+						AssignTo:              &b.cmbType,
+						Model:                 typeLabels,
+						CurrentIndex:          0,
+						OnCurrentIndexChanged: b.onTypeChanged,
+					},
+					cpl.ListBox{
+						AssignTo:              &b.lbFrags,
+						OnCurrentIndexChanged: b.onFragmentChanged,
+					},
+					cpl.Composite{
+						Layout: cpl.HBox{MarginsZero: true},
+						Children: []cpl.Widget{
+							cpl.PushButton{Text: "Export Graph...", OnClicked: func() { b.onExport(false) }},
+							cpl.PushButton{Text: "Export Dependencies...", OnClicked: func() { b.onExport(true) }},
+						},
+					},
+				},
+			},
+			cpl.VSplitter{
+				StretchFactor: 2,
+				Children: []cpl.Widget{
+					cpl.TableView{
+						AssignTo:         &b.tvFields,
+						AlternatingRowBG: true,
+						Columns: []cpl.TableViewColumn{
+							{Name: "Field", Width: 200},
+							{Name: "Value", Width: 300},
+						},
+						Model: b.fields,
+					},
+					cpl.Composite{
+						Layout: cpl.HBox{MarginsZero: true},
+						Children: []cpl.Widget{
+							cpl.GroupBox{
+								Title:  "Depends On",
+								Layout: cpl.VBox{},
+								Children: []cpl.Widget{
+									cpl.ListBox{
+										AssignTo:        &b.lbRefs,
+										OnItemActivated: b.onRefActivated,
+									},
+								},
+							},
+							cpl.GroupBox{
+								Title:  "Referenced By",
+								Layout: cpl.VBox{},
+								Children: []cpl.Widget{
+									cpl.ListBox{
+										AssignTo:        &b.lbUsers,
+										OnItemActivated: b.onUserActivated,
+									},
+								},
+							},
+						},
+					},
+					cpl.GroupBox{
+						Title:  "Bytes (re-encoded, may differ from the file)",
+						Layout: cpl.VBox{},
+						Children: []cpl.Widget{
+							cpl.TextEdit{
+								AssignTo: &b.teHex,
+								ReadOnly: true,
+								VScroll:  true,
+								Font:     cpl.Font{Family: "Consolas", PointSize: 9},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *fragmentBrowser) typeName(idx int) string {
+	return wldfrag.TypeName(b.data.Fragments[idx].FragCode())
+}
+
+func (b *fragmentBrowser) onTypeChanged() {
+	if b.lbFrags == nil || b.isLoading {
+		return
+	}
+	name := ""
+	if i := b.cmbType.CurrentIndex(); i > 0 && i < len(b.types) {
+		name = b.types[i]
+	}
+
+	b.visible = []int{}
+	items := []string{}
+	for _, idx := range b.indexes {
+		if name != "" && b.typeName(idx) != name {
+			continue
+		}
+		b.visible = append(b.visible, idx)
+		items = append(items, b.graph.Label(idx))
+	}
+	b.isLoading = true
+	b.lbFrags.SetModel(items)
+	if len(items) > 0 {
+		b.lbFrags.SetCurrentIndex(0)
+	}
+	b.isLoading = false
+	b.onFragmentChanged()
+}
+
+func (b *fragmentBrowser) onFragmentChanged() {
+	if b.isLoading || b.teHex == nil {
+		return
+	}
+	b.fields.rows = [][]string{}
+	b.refs = nil
+	b.users = nil
+	refItems := []string{}
+	userItems := []string{}
+	hexText := ""
+
+	idx := b.current()
+	if idx >= 0 {
+		frag := b.data.Fragments[idx]
+		for _, field := range wldfrag.Fields(frag) {
+			b.fields.rows = append(b.fields.rows, []string{field.Name, field.Value})
+		}
+		b.refs = b.graph.DependsOn(idx)
+		for _, ref := range b.refs {
+			refItems = append(refItems, fmt.Sprintf("%s -> %s", ref.Field, b.graph.Label(ref.Index)))
+		}
+		b.users = b.graph.ReferencedBy(idx)
+		for _, user := range b.users {
+			userItems = append(userItems, b.graph.Label(user))
+		}
+		data, err := wldfrag.Bytes(frag)
+		if err != nil {
+			hexText = err.Error()
+		} else {
+			hexText = strings.ReplaceAll(hex.Dump(data), "\n", "\r\n")
+		}
+	}
+
+	b.fields.PublishRowsReset()
+	b.lbRefs.SetModel(refItems)
+	b.lbUsers.SetModel(userItems)
+	b.teHex.SetText(hexText)
+}
+
+func (b *fragmentBrowser) onRefActivated() {
+	i := b.lbRefs.CurrentIndex()
+	if i < 0 || i >= len(b.refs) {
+		return
+	}
+	err := b.Select(b.refs[i].Index)
+	if err != nil {
+		popup.Errorf(b.lbRefs.Form(), "go to %s: %s", b.refs[i].Field, err)
+	}
+}
+
+func (b *fragmentBrowser) onUserActivated() {
+	i := b.lbUsers.CurrentIndex()
+	if i < 0 || i >= len(b.users) {
+		return
+	}
+	err := b.Select(b.users[i])
+	if err != nil {
+		popup.Errorf(b.lbUsers.Form(), "go to %d: %s", b.users[i], err)
+	}
+}
+
+// onExport saves the reference graph as Graphviz DOT, either all of it or
+// the selected fragment and its dependencies
+func (b *fragmentBrowser) onExport(isSelected bool) {
+	roots := []int{}
+	name := strings.TrimSuffix(b.data.FileName(), filepath.Ext(b.data.FileName()))
+	if isSelected {
+		idx := b.current()
+		if idx < 0 {
+			return
+		}
+		roots = append(roots, idx)
+		name = fmt.Sprintf("%s_%d", name, idx)
+	}
+
+	path, err := popup.Save(b.lbFrags.Form(), "Export Reference Graph", "Graphviz DOT (*.dot)|*.dot", ".", name+".dot")
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(b.lbFrags.Form(), "export graph: %s", err)
+		return
+	}
+	w, err := os.Create(path)
+	if err != nil {
+		popup.Errorf(b.lbFrags.Form(), "export graph: %s", err)
+		return
+	}
+	defer w.Close()
+	err = b.graph.WriteDOT(w, roots...)
+	if err != nil {
+		popup.Errorf(b.lbFrags.Form(), "export graph: %s", err)
+	}
+}
+
+// current is the selected fragment index, or -1
+func (b *fragmentBrowser) current() int {
+	i := b.lbFrags.CurrentIndex()
+	if i < 0 || i >= len(b.visible) {
+		return -1
+	}
+	return b.visible[i]
+}
+
+// Select shows fragment idx, clearing the type filter if it hides it
+func (b *fragmentBrowser) Select(idx int) error {
+	if !b.graph.Has(idx) {
+		return fmt.Errorf("fragment %d does not exist", idx)
+	}
+	pos := b.visiblePos(idx)
+	if pos < 0 {
+		b.isLoading = true
+		b.cmbType.SetCurrentIndex(0)
+		b.isLoading = false
+		b.onTypeChanged()
+		pos = b.visiblePos(idx)
+	}
+	if pos < 0 {
+		return fmt.Errorf("fragment %d not listed", idx)
+	}
+	return b.lbFrags.SetCurrentIndex(pos)
+}
+
+func (b *fragmentBrowser) visiblePos(idx int) int {
+	for i, v := range b.visible {
+		if v == idx {
+			return i
+		}
+	}
+	return -1
+}
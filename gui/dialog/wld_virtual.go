@@ -114,6 +114,10 @@ func ShowWldVirtualEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) e
 	}
 	tabWidget.Pages = append(tabWidget.Pages, *bspPage)
 
+	fragmentPage := &cpl.TabPage{}
+	browser := virtualFragmentPage(rawData, fragmentPage)
+	tabWidget.Pages = append(tabWidget.Pages, *fragmentPage)
+
 	formElements.Children = append(formElements.Children, tabWidget)
 
 	onSave := func() error {
@@ -139,7 +143,7 @@ func ShowWldVirtualEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) e
 		Title:         fmt.Sprintf("%s (Virtual)", data.FileName),
 		DefaultButton: &savePB,
 		CancelButton:  &cancelPB,
-		MinSize:       cpl.Size{Width: 300, Height: 300},
+		MinSize:       cpl.Size{Width: 900, Height: 600},
 		Layout:        cpl.VBox{},
 		Children: []cpl.Widget{
 			formElements,
@@ -168,11 +172,13 @@ func ShowWldVirtualEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) e
 			},
 		},
 	}
-	result, err := dia.Run(mw)
+	err = dia.Create(mw)
 	if err != nil {
-		return fmt.Errorf("run dialog: %w", err)
+		return fmt.Errorf("create dialog: %w", err)
 	}
+	browser.onTypeChanged()
 
+	result := dlg.Run()
 	if result != walk.DlgCmdOK {
 		return fmt.Errorf("cancelled")
 	}
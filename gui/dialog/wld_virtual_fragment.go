@@ -0,0 +1,17 @@
+package dialog
+
+import (
+	"github.com/xackery/quail/raw"
+	"github.com/xackery/wlk/cpl"
+)
+
+// virtualFragmentPage browses the raw fragments behind the virtual wld and
+// how they reference each other
+func virtualFragmentPage(data *raw.Wld, page *cpl.TabPage) *fragmentBrowser {
+	browser := newFragmentBrowser(data)
+
+	page.Title = "Fragments"
+	page.Layout = cpl.VBox{}
+	page.Children = []cpl.Widget{browser.Widget()}
+	return browser
+}
@@ -2,7 +2,10 @@ package dialog
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/xackery/quail-gui/popup"
 	"github.com/xackery/quail/raw"
 	"github.com/xackery/wlk/cpl"
 	"github.com/xackery/wlk/walk"
@@ -15,95 +18,34 @@ func ShowZonEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 		return fmt.Errorf("cast zon")
 	}
 
-	defaultModel := ""
-	if len(data.Models) > 0 {
-		defaultModel = data.Models[0]
-	}
-	models := []string{}
-	for _, model := range data.Models {
-		models = append(models, model)
-	}
-
-	defaultObject := ""
-	if len(data.Objects) > 0 {
-		defaultObject = data.Objects[0].ModelName
-	}
-	objects := []string{}
-	for _, object := range data.Objects {
-		objects = append(objects, object.ModelName)
-	}
-
-	defaultRegion := ""
-	if len(data.Regions) > 0 {
-		defaultRegion = data.Regions[0].Name
-	}
-	regions := []string{}
-	for _, region := range data.Regions {
-		regions = append(regions, region.Name)
+	zone := &zoneData{
+		Models:  append([]string{}, data.Models...),
+		Objects: append([]raw.ZonObject{}, data.Objects...),
+		Regions: append([]raw.ZonRegion{}, data.Regions...),
 	}
 
+	var cmbVersion *walk.ComboBox
 	formElements := cpl.Composite{
 		Layout: cpl.VBox{},
 		Children: []cpl.Widget{
-			cpl.GenerateComposite(cpl.Grid{Columns: 2},
-				cpl.Label{Text: "Version:"},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    fmt.Sprintf("%d", data.Version),
-					Model:    []string{"1", "2", "3"},
-				},
-			),
-			cpl.Label{Text: "Models:"},
-			cpl.ListBox{
-				Model: models,
-				OnItemActivated: func() {
-					fmt.Println("item activated: ", data.Models)
-				},
-			},
-			cpl.Label{Text: "Models:"},
-			cpl.GenerateComposite(cpl.Grid{Columns: 3},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    defaultModel,
-					Model:    models,
-				},
-				cpl.PushButton{
-					Text: "Add",
-				},
-				cpl.PushButton{
-					Text: "Edit",
-				},
-			),
-			cpl.Label{Text: "Objects:"},
-			cpl.GenerateComposite(cpl.Grid{Columns: 3},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    defaultObject,
-					Model:    objects,
-				},
-				cpl.PushButton{
-					Text: "Add",
-				},
-				cpl.PushButton{
-					Text: "Edit",
-				},
-			),
-			cpl.Label{Text: "Regions:"},
-			cpl.GenerateComposite(cpl.Grid{Columns: 3},
-				cpl.ComboBox{
-					Editable: false,
-					Value:    defaultRegion,
-					Model:    regions,
-				},
-				cpl.PushButton{
-					Text: "Add",
-				},
-				cpl.PushButton{
-					Text: "Edit",
-				},
-			),
+			versionWidget(&cmbVersion, data.Version),
 		},
 	}
+	for _, editor := range zone.editors() {
+		formElements.Children = append(formElements.Children, editor.Widget())
+	}
+
+	onSave := func() error {
+		newVersion, err := strconv.Atoi(cmbVersion.Text())
+		if err != nil {
+			return fmt.Errorf("parse version: %w", err)
+		}
+		data.Version = uint32(newVersion)
+		data.Models = zone.Models
+		data.Objects = zone.Objects
+		data.Regions = zone.Regions
+		return nil
+	}
 
 	var dlg *walk.Dialog
 	dia := cpl.Dialog{
@@ -111,7 +53,7 @@ func ShowZonEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 		Title:         data.FileName(),
 		DefaultButton: &savePB,
 		CancelButton:  &cancelPB,
-		MinSize:       cpl.Size{Width: 300, Height: 300},
+		MinSize:       cpl.Size{Width: 600, Height: 500},
 		Layout:        cpl.VBox{},
 		Children: []cpl.Widget{
 			formElements,
@@ -119,16 +61,23 @@ func ShowZonEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 				Layout: cpl.HBox{},
 				Children: []cpl.Widget{
 					cpl.HSpacer{},
-					cpl.PushButton{
-						AssignTo:  &savePB,
-						Text:      "Save",
-						OnClicked: func() { dlg.Accept() },
-					},
 					cpl.PushButton{
 						AssignTo:  &cancelPB,
 						Text:      "Cancel",
 						OnClicked: func() { dlg.Cancel() },
 					},
+					cpl.PushButton{
+						AssignTo: &savePB,
+						Text:     "Save",
+						OnClicked: func() {
+							err := onSave()
+							if err != nil {
+								popup.Errorf(dlg, "save: %s", err.Error())
+								return
+							}
+							dlg.Accept()
+						},
+					},
 				},
 			},
 		},
@@ -143,3 +92,252 @@ func ShowZonEdit(mw *walk.MainWindow, title string, src raw.ReadWriter) error {
 
 	return nil
 }
+
+// zoneData is an editable copy of a zon's models, object placements and regions
+type zoneData struct {
+	Models  []string
+	Objects []raw.ZonObject
+	Regions []raw.ZonRegion
+}
+
+func (z *zoneData) editors() []*collectionEditor {
+	return []*collectionEditor{
+		{Title: "Models", Items: z.modelItems, Add: z.addModel, Edit: z.editModel, Delete: z.deleteModel},
+		{
+			Title: "Objects",
+			Columns: []cpl.TableViewColumn{
+				{Name: "Model", Width: 120},
+				{Name: "Instance", Width: 120},
+				{Name: "Position", Width: 140},
+				{Name: "Rotation", Width: 100},
+				{Name: "Scale", Width: 50},
+			},
+			Rows:   z.objectRows,
+			Add:    z.addObject,
+			Edit:   z.editObject,
+			Delete: z.deleteObject,
+		},
+		{
+			Title: "Regions",
+			Columns: []cpl.TableViewColumn{
+				{Name: "Name", Width: 120},
+				{Name: "Center", Width: 140},
+				{Name: "Extent", Width: 140},
+			},
+			Rows:   z.regionRows,
+			Add:    z.addRegion,
+			Edit:   z.editRegion,
+			Delete: z.deleteRegion,
+		},
+	}
+}
+
+func (z *zoneData) modelItems() []string {
+	return append([]string{}, z.Models...)
+}
+
+func (z *zoneData) addModel(owner walk.Form) error {
+	name, err := z.showModel(owner, "Add Model", "", -1)
+	if err != nil {
+		return err
+	}
+	z.Models = append(z.Models, name)
+	return nil
+}
+
+// editModel renames a model reference and the objects placed from it
+func (z *zoneData) editModel(owner walk.Form, idx int) error {
+	oldName := z.Models[idx]
+	name, err := z.showModel(owner, "Edit Model", oldName, idx)
+	if err != nil {
+		return err
+	}
+	z.Models[idx] = name
+	for i := range z.Objects {
+		if z.Objects[i].ModelName == oldName {
+			z.Objects[i].ModelName = name
+		}
+	}
+	return nil
+}
+
+func (z *zoneData) deleteModel(owner walk.Form, idx int) error {
+	name := z.Models[idx]
+	for i, object := range z.Objects {
+		if object.ModelName == name {
+			return fmt.Errorf("%s is placed by object %d", name, i)
+		}
+	}
+	z.Models = append(z.Models[:idx], z.Models[idx+1:]...)
+	return nil
+}
+
+func (z *zoneData) showModel(owner walk.Form, title string, name string, skip int) (string, error) {
+	fields := []*formField{
+		{Label: "Name", Text: name, Parse: func(text string) error {
+			if text == "" {
+				return fmt.Errorf("required")
+			}
+			for i, other := range z.Models {
+				if i != skip && strings.EqualFold(other, text) {
+					return fmt.Errorf("%s already exists", text)
+				}
+			}
+			name = text
+			return nil
+		}},
+	}
+	err := showFormEdit(owner, title, fields)
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (z *zoneData) objectRows() [][]string {
+	rows := [][]string{}
+	for _, object := range z.Objects {
+		rows = append(rows, []string{
+			object.ModelName,
+			object.InstanceName,
+			formatFloats(object.Translation[:]...),
+			formatFloats(object.Rotation[:]...),
+			formatFloats(object.Scale),
+		})
+	}
+	return rows
+}
+
+func (z *zoneData) addObject(owner walk.Form) error {
+	object := raw.ZonObject{Scale: 1}
+	if len(z.Models) > 0 {
+		object.ModelName = z.Models[0]
+	}
+	err := z.showObject(owner, "Add Object", &object)
+	if err != nil {
+		return err
+	}
+	z.Objects = append(z.Objects, object)
+	return nil
+}
+
+func (z *zoneData) editObject(owner walk.Form, idx int) error {
+	return z.showObject(owner, "Edit Object", &z.Objects[idx])
+}
+
+func (z *zoneData) deleteObject(owner walk.Form, idx int) error {
+	z.Objects = append(z.Objects[:idx], z.Objects[idx+1:]...)
+	return nil
+}
+
+func (z *zoneData) showObject(owner walk.Form, title string, object *raw.ZonObject) error {
+	edit := *object
+	fields := []*formField{
+		{Label: "Model", Text: edit.ModelName, Parse: func(text string) error {
+			for _, model := range z.Models {
+				if model == text {
+					edit.ModelName = text
+					return nil
+				}
+			}
+			return fmt.Errorf("%s is not a model of this zone", text)
+		}},
+		{Label: "Instance", Text: edit.InstanceName, Parse: func(text string) error {
+			edit.InstanceName = text
+			return nil
+		}},
+		{Label: "Position", Text: formatFloats(edit.Translation[:]...), Parse: func(text string) error {
+			return parseFloatsInto(text, edit.Translation[:])
+		}},
+		{Label: "Rotation", Text: formatFloats(edit.Rotation[:]...), Parse: func(text string) error {
+			return parseFloatsInto(text, edit.Rotation[:])
+		}},
+		{Label: "Scale", Text: formatFloats(edit.Scale), Parse: func(text string) error {
+			values, err := parseFloats(text, 1)
+			if err != nil {
+				return err
+			}
+			if values[0] <= 0 {
+				return fmt.Errorf("must be greater than 0")
+			}
+			edit.Scale = values[0]
+			return nil
+		}},
+	}
+	err := showFormEdit(owner, title, fields)
+	if err != nil {
+		return err
+	}
+	*object = edit
+	return nil
+}
+
+func (z *zoneData) regionRows() [][]string {
+	rows := [][]string{}
+	for _, region := range z.Regions {
+		rows = append(rows, []string{
+			region.Name,
+			formatFloats(region.Center[:]...),
+			formatFloats(region.Extent[:]...),
+		})
+	}
+	return rows
+}
+
+func (z *zoneData) addRegion(owner walk.Form) error {
+	region := raw.ZonRegion{}
+	err := z.showRegion(owner, "Add Region", &region, -1)
+	if err != nil {
+		return err
+	}
+	z.Regions = append(z.Regions, region)
+	return nil
+}
+
+func (z *zoneData) editRegion(owner walk.Form, idx int) error {
+	return z.showRegion(owner, "Edit Region", &z.Regions[idx], idx)
+}
+
+func (z *zoneData) deleteRegion(owner walk.Form, idx int) error {
+	z.Regions = append(z.Regions[:idx], z.Regions[idx+1:]...)
+	return nil
+}
+
+func (z *zoneData) showRegion(owner walk.Form, title string, region *raw.ZonRegion, skip int) error {
+	edit := *region
+	fields := []*formField{
+		{Label: "Name", Text: edit.Name, Parse: func(text string) error {
+			if text == "" {
+				return fmt.Errorf("required")
+			}
+			for i, other := range z.Regions {
+				if i != skip && strings.EqualFold(other.Name, text) {
+					return fmt.Errorf("%s already exists", text)
+				}
+			}
+			edit.Name = text
+			return nil
+		}},
+		{Label: "Center", Text: formatFloats(edit.Center[:]...), Parse: func(text string) error {
+			return parseFloatsInto(text, edit.Center[:])
+		}},
+		{Label: "Extent", Text: formatFloats(edit.Extent[:]...), Parse: func(text string) error {
+			err := parseFloatsInto(text, edit.Extent[:])
+			if err != nil {
+				return err
+			}
+			for _, v := range edit.Extent {
+				if v < 0 {
+					return fmt.Errorf("extents can't be negative")
+				}
+			}
+			return nil
+		}},
+	}
+	err := showFormEdit(owner, title, fields)
+	if err != nil {
+		return err
+	}
+	*region = edit
+	return nil
+}
@@ -1,15 +1,41 @@
 package gui
 
-import "github.com/xackery/quail-gui/slog"
+import (
+	"path/filepath"
 
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/session"
+	"github.com/xackery/quail-gui/slog"
+)
+
+// onDrop opens dropped archives and imports any other dropped files into the active archive
 func onDrop(files []string) {
 	if len(files) == 0 {
 		slog.Println("Ignoring file drop, no files")
 		return
 	}
-	if len(files) > 1 {
-		slog.Println("Ignoring file drop, too many files")
-		return
+
+	archives := []string{}
+	imports := []string{}
+	for _, path := range files {
+		if session.IsArchiveExt(filepath.Ext(path)) {
+			archives = append(archives, path)
+			continue
+		}
+		imports = append(imports, path)
+	}
+
+	if len(imports) > 0 {
+		importFiles(imports)
+	}
+
+	for _, path := range archives {
+		err := Open(path)
+		if err != nil {
+			if err.Error() == "cancelled" {
+				continue
+			}
+			popup.Errorf(mw, "open %s: %s", filepath.Base(path), err)
+		}
 	}
-	slog.Printf("File dropped: %s\n", files[0])
 }
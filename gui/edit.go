@@ -3,6 +3,8 @@ package gui
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/xackery/quail-gui/config"
 	"github.com/xackery/quail-gui/gui/dialog"
@@ -34,11 +36,13 @@ func DialogEdit(itemName string, value raw.ReadWriter) ([]byte, error) {
 	slog.Println("Asserted type:", valueType)
 
 	extFuncs := map[string]func(*walk.MainWindow, string, raw.ReadWriter) error{
-		"mod":       dialog.ShowModEdit,
-		"zon":       dialog.ShowZonEdit,
-		"wld.ascii": dialog.ShowWldAsciiEdit,
-		"mds":       dialog.ShowMdsEdit,
-		"txt":       dialog.ShowTxtEdit,
+		"mod": dialog.ShowModEdit,
+		"zon": dialog.ShowZonEdit,
+		"wld.ascii": func(mw *walk.MainWindow, title string, value raw.ReadWriter) error {
+			return dialog.ShowWldAsciiEdit(mw, title, value, wldIncludes(itemName))
+		},
+		"mds": dialog.ShowMdsEdit,
+		"txt": dialog.ShowTxtEdit,
 	}
 	cfg := config.Instance()
 
@@ -64,3 +68,18 @@ func DialogEdit(itemName string, value raw.ReadWriter) ([]byte, error) {
 	}
 	return nil, fmt.Errorf("unsupported file type: %s", valueType)
 }
+
+// wldIncludes reads the files included by the ASCII WLD itemName, from the open
+// archive when it is one of its entries and from its directory otherwise
+func wldIncludes(itemName string) func(name string) ([]byte, error) {
+	if current != nil && current.Has(itemName) {
+		sess := current
+		return func(name string) ([]byte, error) {
+			return sess.File(name)
+		}
+	}
+	dir := filepath.Dir(itemName)
+	return func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+}
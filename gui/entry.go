@@ -6,10 +6,11 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/xackery/quail-gui/gui/component"
-	"github.com/xackery/quail-gui/ico"
+	"github.com/xackery/quail-gui/gui/dialog"
 	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/session"
 	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/quail-gui/texture"
 	"github.com/xackery/quail/raw"
 	"github.com/xackery/wlk/walk"
 )
@@ -20,6 +21,9 @@ var (
 	menuEntryEdit   *walk.Action
 	menuEntryDelete *walk.Action
 	menuEntryRename *walk.Action
+
+	menuEntryBulkRename *walk.Action
+	menuEntrySelectAll  *walk.Action
 )
 
 func onMenuEntryNew() {
@@ -27,7 +31,7 @@ func onMenuEntryNew() {
 	var err error
 	var data []byte
 	for {
-		value, err = popup.InputBox(mw, "New entry", "Create a new entry in "+filepath.Base(archivePath), "Name", value)
+		value, err = popup.InputBox(mw, "New entry", "Create a new entry in "+current.Name(), "Name", value)
 		if err != nil {
 			if err.Error() == "cancelled" {
 				return
@@ -36,82 +40,29 @@ func onMenuEntryNew() {
 			return
 		}
 
-		isNew := true
-		for _, entry := range archive.Files() {
-			if strings.EqualFold(entry.Name(), value) {
-				popup.Errorf(mw, "file %s already exists", value)
-				isNew = false
-				break
-			}
-		}
-		if !isNew {
+		if current.Has(value) {
+			popup.Errorf(mw, "file %s already exists", value)
 			continue
 		}
 
-		ext := filepath.Ext(value)
-		if ext == "" {
-			popup.Errorf(mw, "entry must have an extension")
-			continue
-		}
-		baseName := strings.ReplaceAll(value, ext, "")
-
-		var rawWriter raw.ReadWriter
-
-		switch ext {
-		case ".mod":
-			rawWriter = &raw.Mod{MetaFileName: value}
-		case ".txt":
-			data = []byte(value)
-		case ".wld":
-			if baseName == "objects" {
-				setJumpObjectEnabled(true)
-			} else if baseName == "lights" {
-				setJumpLightEnabled(true)
-			} else {
-				setJumpWorldEnabled(true)
-			}
-			rawWriter = &raw.Wld{MetaFileName: value}
-		case ".zon":
-			setJumpWorldEnabled(true)
-			rawWriter = &raw.Zon{MetaFileName: value}
-		default:
-			popup.Errorf(mw, "unsupported extension %s", ext)
+		data, err = session.NewEntryData(value)
+		if err != nil {
+			popup.Errorf(mw, "new entry: %s", err)
 			continue
 		}
 
-		if rawWriter != nil {
-			buf := bytes.NewBuffer([]byte{})
-			err = rawWriter.Write(buf)
-			if err != nil {
-				popup.Errorf(mw, "write mod: %s", err)
-				return
-			}
-			data = buf.Bytes()
-		}
-
 		break
 	}
 
-	err = archive.SetFile(value, data)
+	err = current.Add(value, data)
 	if err != nil {
-		popup.Errorf(mw, "set file %s: %s", value, err)
+		popup.Errorf(mw, "add %s: %s", value, err)
 		return
 	}
 
-	ext := strings.ToLower(filepath.Ext(value))
-	img, err := ico.Generate(ext, data)
-	if err != nil {
-		slog.Printf("Failed to generate icon for %s: %s\n", value, err.Error())
-		img = ico.Grab("unk")
-	}
-
-	fileView.AddItem(&component.FileViewEntry{
-		Icon:    img,
-		Name:    value,
-		Ext:     ext,
-		Size:    generateSize(len(data)),
-		RawSize: len(data),
-	})
+	refreshEntries()
+	updateJumps()
+	updateTitle()
 }
 
 func onMenuEntryEdit() {
@@ -119,29 +70,36 @@ func onMenuEntryEdit() {
 }
 
 func onMenuEntryDelete() {
-	if file.CurrentIndex() < 0 {
+	names := selectedNames()
+	if len(names) == 0 {
 		return
 	}
-	item := fileView.Item(file.CurrentIndex())
-	if item == nil {
-		return
+	msg := "Are you sure you want to delete " + names[0] + "?"
+	if len(names) > 1 {
+		msg = fmt.Sprintf("Are you sure you want to delete %d entries?\n\n%s", len(names), strings.Join(names, "\n"))
 	}
-	if !popup.MessageBoxYesNo(mw, "Delete entry", "Are you sure you want to delete "+item.Name+"?") {
+	if !popup.MessageBoxYesNo(mw, "Delete entry", msg) {
 		return
 	}
 
-	err := archive.Remove(item.Name)
+	err := current.DeleteAll(names)
 	if err != nil {
-		popup.Errorf(mw, "delete file %s: %s", item.Name, err)
+		popup.Errorf(mw, "delete: %s", err)
 		return
 	}
-	fileView.RemoveItem(file.CurrentIndex())
-	slog.Printf("Deleted %s\n", item.Name)
+	refreshEntries()
+	updateJumps()
+	updateTitle()
+	slog.Printf("Deleted %s\n", strings.Join(names, ", "))
 }
 
 func onMenuEntryRename() {
 	var value string
 	var err error
+	if len(selectedNames()) > 1 {
+		onMenuEntryBulkRename()
+		return
+	}
 	if file.CurrentIndex() < 0 {
 		slog.Printf("Select an entry to rename\n")
 		return
@@ -164,24 +122,99 @@ func onMenuEntryRename() {
 			return
 		}
 
-		for _, entry := range archive.Files() {
-			if !strings.EqualFold(entry.Name(), item.Name) {
-				continue
-			}
-			entry.SetName(value)
-			item.Name = value + "*"
-			isEdited = true
-			err = mw.SetTitle(fmt.Sprintf("%s*", mw.Title()))
-			if err != nil {
-				popup.Errorf(mw, "set title: %s", err)
-				return
-			}
+		err = current.Rename(itemName, value)
+		if err != nil {
+			popup.Errorf(mw, "rename %s: %s", itemName, err)
+			continue
+		}
+		refreshEntries()
+		updateJumps()
+		updateTitle()
+
+		slog.Printf("Renamed %s to %s\n", itemName, value)
+		return
+	}
+
+}
+
+func onMenuEntryBulkRename() {
+	if current == nil {
+		return
+	}
+	names := selectedNames()
+	if len(names) < 2 {
+		names = []string{}
+		for _, fe := range current.Files() {
+			names = append(names, fe.Name())
+		}
+	}
 
-			slog.Printf("Renamed %s to %s\n", item.Name, value)
+	renames, err := dialog.ShowBulkRename(mw, names)
+	if err != nil {
+		if err.Error() == "cancelled" {
 			return
 		}
+		popup.Errorf(mw, "bulk rename: %s", err)
+		return
 	}
 
+	err = current.RenameAll(renames)
+	if err != nil {
+		popup.Errorf(mw, "bulk rename: %s", err)
+		return
+	}
+	refreshEntries()
+	updateJumps()
+	updateTitle()
+	slog.Printf("Renamed %d entries\n", len(renames))
+}
+
+func onMenuEntrySelectAll() {
+	if file == nil {
+		return
+	}
+	indexes := []int{}
+	for i := 0; i < fileView.RowCount(); i++ {
+		indexes = append(indexes, i)
+	}
+	err := file.SetSelectedIndexes(indexes)
+	if err != nil {
+		slog.Printf("Failed to select all: %s\n", err.Error())
+	}
+}
+
+// selectedNames returns the entry names of every selected row, without the edited marker
+func selectedNames() []string {
+	names := []string{}
+	if file == nil || fileView == nil {
+		return names
+	}
+	indexes := file.SelectedIndexes()
+	if len(indexes) == 0 && file.CurrentIndex() >= 0 {
+		indexes = []int{file.CurrentIndex()}
+	}
+	for _, idx := range indexes {
+		if idx < 0 || idx >= fileView.RowCount() {
+			continue
+		}
+		names = append(names, strings.ReplaceAll(fileView.Item(idx).Name, "*", ""))
+	}
+	return names
+}
+
+// refreshEntries rebuilds the rows of the active archive, keeping the selection where possible
+func refreshEntries() {
+	lastSelection := file.CurrentIndex()
+	fileView.SetItems(fileViewEntries())
+	if lastSelection >= fileView.RowCount() {
+		lastSelection = fileView.RowCount() - 1
+	}
+	if lastSelection >= 0 {
+		file.SetCurrentIndex(lastSelection)
+	}
+	entrySetActive(fileView.RowCount() > 0 && file.CurrentIndex() >= 0)
+	updateFilterStatus()
+	updatePreview()
 }
 
 func onEntryChange() {
@@ -192,10 +225,12 @@ func onEntryChange() {
 		entrySetActive(false)
 		return
 	}
+	lastEntry = file.CurrentIndex()
 	entrySetActive(true)
 	fileName := fileView.Item(file.CurrentIndex()).Name
 
 	slog.Printf("Selected %s\n", fileName)
+	updatePreview()
 }
 
 func onEntryActivate() {
@@ -220,20 +255,28 @@ func EntryEdit() {
 		return
 	}
 
-	if archive == nil {
+	if current == nil {
 		slog.Println("archive is nil")
 		return
 	}
 
 	itemName := strings.ReplaceAll(item.Name, "*", "")
 
-	data, err := archive.File(itemName)
+	data, err := current.File(itemName)
 	if err != nil {
 		popup.Errorf(mw, "open file %s: %s", itemName, err)
 		return
 	}
 
 	ext := filepath.Ext(strings.ToLower(itemName))
+	if texture.IsTextureExt(ext) {
+		err = dialog.ShowTextureView(mw, itemName, data)
+		if err != nil {
+			popup.Errorf(mw, "show texture %s: %s", itemName, err)
+		}
+		return
+	}
+
 	value, err := raw.Read(ext, bytes.NewReader(data))
 	if err != nil {
 		popup.Errorf(mw, "read raw %s: %s", itemName, err)
@@ -242,11 +285,11 @@ func EntryEdit() {
 	value.SetFileName(itemName)
 
 	if itemName == "objects.wld" || itemName == "lights.wld" {
-		archiveBaseName := filepath.Base(archivePath)
+		archiveBaseName := current.Name()
 		if strings.HasSuffix(archiveBaseName, ".s3d") {
 			archiveBaseName = archiveBaseName[:len(archiveBaseName)-4] + ".wld"
 		}
-		wldData, err := archive.File(archiveBaseName)
+		wldData, err := current.File(archiveBaseName)
 		if err != nil {
 			popup.Errorf(mw, "open file %s: %s", archiveBaseName, err)
 			return
@@ -271,31 +314,24 @@ func EntryEdit() {
 		return
 	}
 
-	err = archive.SetFile(itemName, data)
+	err = current.Replace(itemName, data)
 	if err != nil {
-		popup.Errorf(mw, "set file %s: %s", itemName, err)
+		popup.Errorf(mw, "replace %s: %s", itemName, err)
 		return
 	}
 	slog.Printf("Edited %s\n", itemName)
-	item.Size = generateSize(len(data))
-	item.RawSize = len(data)
-	item.Name = strings.ReplaceAll(item.Name, "*", "")
-	item.Name = fmt.Sprintf("%s*", itemName)
-
-	if !isEdited {
-		isEdited = true
-		err = mw.SetTitle(fmt.Sprintf("%s*", mw.Title()))
-		if err != nil {
-			popup.Errorf(mw, "set title: %s", err)
-			return
-		}
-	}
-
+	refreshEntries()
+	updateTitle()
 }
 
 func entrySetActive(value bool) {
-	menuEntryNew.SetEnabled(value)
+	menuEntryNew.SetEnabled(current != nil)
+	menuEntryImport.SetEnabled(current != nil)
 	menuEntryEdit.SetEnabled(value)
 	menuEntryDelete.SetEnabled(value)
 	menuEntryRename.SetEnabled(value)
+	menuEntryBulkRename.SetEnabled(current != nil)
+	menuEntryConvertTexture.SetEnabled(value)
+	menuEntryExtract.SetEnabled(value)
+	menuEntryExtractAll.SetEnabled(current != nil)
 }
@@ -0,0 +1,84 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/xackery/quail-gui/gui/dialog"
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/quail-gui/texture"
+	"github.com/xackery/wlk/walk"
+)
+
+var (
+	menuEntryExtract    *walk.Action
+	menuEntryExtractAll *walk.Action
+)
+
+func onMenuEntryExtract() {
+	if current == nil {
+		return
+	}
+	names := selectedNames()
+	if len(names) == 0 {
+		slog.Printf("Select an entry to extract\n")
+		return
+	}
+	extractEntries(names)
+}
+
+func onMenuEntryExtractAll() {
+	if current == nil {
+		return
+	}
+	names := []string{}
+	for _, fe := range current.Files() {
+		names = append(names, fe.Name())
+	}
+	extractEntries(names)
+}
+
+// extractEntries writes entries of the active archive to a folder the user picks
+func extractEntries(names []string) {
+	if len(names) == 0 {
+		slog.Printf("Nothing to extract\n")
+		return
+	}
+
+	dir, err := popup.BrowseFolder(mw, fmt.Sprintf("Extract %d entries to", len(names)), filepath.Dir(current.Path()))
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(mw, "extract: %s", err)
+		return
+	}
+
+	textureCount := 0
+	for _, name := range names {
+		if texture.IsTextureExt(filepath.Ext(name)) {
+			textureCount++
+		}
+	}
+	isPNG := false
+	if textureCount > 0 {
+		isPNG = popup.MessageBoxYesNo(mw, "Extract", fmt.Sprintf("Decode %d textures to PNG?", textureCount))
+	}
+
+	var paths []string
+	err = dialog.ShowProgress(mw, "Extracting "+current.Name(), func(progress func(done int, total int, text string) error) error {
+		extracted, err := current.Extract(dir, names, isPNG, progress)
+		paths = extracted
+		return err
+	})
+	if err != nil {
+		if err.Error() == "cancelled" {
+			slog.Printf("Cancelled extract after %d entries\n", len(paths))
+			return
+		}
+		popup.Errorf(mw, "extract: %s", err)
+		return
+	}
+	slog.Printf("Extracted %d entries to %s\n", len(paths), dir)
+}
@@ -1,35 +1,94 @@
 package gui
 
 import (
-	"os"
+	"fmt"
 	"path/filepath"
 	"strings"
 
-	"github.com/xackery/quail-gui/gui/component"
-	"github.com/xackery/quail-gui/ico"
+	"github.com/xackery/quail-gui/gui/dialog"
 	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/session"
 	"github.com/xackery/quail-gui/slog"
 	"github.com/xackery/wlk/walk"
 )
 
 var (
-	menuFileNew        *walk.Action
-	menuFileOpen       *walk.Action
-	menuFileOpenRecent *walk.Action
-	menuFileRefresh    *walk.Action
-	menuFileDelete     *walk.Action
-	menuFileSave       *walk.Action
-	menuFileSaveAs     *walk.Action
-	menuFileClose      *walk.Action
-	menuFileExit       *walk.Action
+	menuFileNew           *walk.Action
+	menuFileOpen          *walk.Action
+	menuFileOpenRecent    *walk.Menu
+	menuFileRefresh       *walk.Action
+	menuFileDelete        *walk.Action
+	menuFileSave          *walk.Action
+	menuFileSaveAs        *walk.Action
+	menuFileRestoreBackup *walk.Action
+	menuFileClose         *walk.Action
+	menuFileExit          *walk.Action
 )
 
+const archiveFilter = "All Archives|*.pfs;*.eqg;*.s3d;*.pak|PFS Files (*.pfs)|*.pfs|EQG Files (*.eqg)|*.eqg|S3D Files (*.s3d)|*.s3d|PAK Files (*.pak)|*.pak"
+
 func onFileNew() {
-	slog.Println("new triggered")
+	names := []string{}
+	for _, tmpl := range session.Templates {
+		names = append(names, fmt.Sprintf("%s (%s)", tmpl.Name, tmpl.Ext))
+	}
+	idx, err := dialog.ShowNewArchive(mw, names)
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(mw, "new archive: %s", err)
+		return
+	}
+	tmpl := session.Templates[idx]
+
+	ext := strings.TrimPrefix(tmpl.Ext, ".")
+	filter := fmt.Sprintf("%s Files (*.%s)|*.%s", strings.ToUpper(ext), ext, ext)
+	path, err := popup.Save(mw, "New EQ Archive", filter, ".", "new"+tmpl.Ext)
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(mw, "new archive: %s", err)
+		return
+	}
+	if filepath.Ext(path) == "" {
+		path += tmpl.Ext
+	}
+	if !strings.EqualFold(filepath.Ext(path), tmpl.Ext) {
+		popup.Errorf(mw, "new archive: %s template must be saved as %s", tmpl.Name, tmpl.Ext)
+		return
+	}
+	if tabByPath(path) != nil {
+		popup.Errorf(mw, "new archive: %s is already open", filepath.Base(path))
+		return
+	}
+
+	sess, err := session.New(path, tmpl)
+	if err != nil {
+		popup.Errorf(mw, "new archive: %s", err)
+		return
+	}
+	err = sess.Save()
+	if err != nil {
+		popup.Errorf(mw, "new archive save: %s", err)
+		return
+	}
+	err = sess.Close()
+	if err != nil {
+		slog.Printf("Failed to close %s: %s\n", sess.Name(), err.Error())
+	}
+
+	err = Open(path)
+	if err != nil {
+		popup.Errorf(mw, "gui open: %s", err)
+		return
+	}
+	slog.Printf("Created %s\n", filepath.Base(path))
 }
 
 func onFileOpen() {
-	path, err := popup.Open(mw, "Open EQ Archive", "All Archives|*.pfs;*.eqg;*.s3d;*.pak|PFS Files (*.pfs)|*.pfs|EQG Files (*.eqg)|*.eqg|S3D Files (*.s3d)|*.s3d|PAK Files (*.pak)|*.pak|All Files (*.*)|*.*", ".")
+	path, err := popup.Open(mw, "Open EQ Archive", archiveFilter+"|All Files (*.*)|*.*", ".")
 	if err != nil {
 		if err.Error() == "cancelled" {
 			return
@@ -40,18 +99,24 @@ func onFileOpen() {
 	slog.Printf("Menu Opening %s\n", path)
 	err = Open(path)
 	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
 		popup.Errorf(mw, "gui open: %s", err)
 		return
 	}
 }
 
-func onFileOpenRecent() {
-	slog.Println("open recent triggered")
-}
-
 func onFileRefresh() {
-	err := Open(archivePath)
+	if current == nil {
+		slog.Println("No archive to refresh")
+		return
+	}
+	err := Open(current.Path())
 	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
 		popup.Errorf(mw, "refresh: %s", err)
 		return
 	}
@@ -62,66 +127,137 @@ func onFileDelete() {
 }
 
 func onFileSave() {
-	if archive == nil {
+	if current == nil {
 		popup.Errorf(mw, "file save: nothing to save")
 		return
 	}
 
-	w, err := os.Create(archivePath)
+	err := current.Save()
 	if err != nil {
-		popup.Errorf(mw, "create: %s", err)
+		popup.Errorf(mw, "save: %s", err)
 		return
 	}
-	defer w.Close()
 
-	err = archive.Write(w)
-	if err != nil {
-		popup.Errorf(mw, "save: %s", err)
+	updateTitle()
+
+	lastSelection := file.CurrentIndex()
+
+	entries := fileViewEntries()
+	fileView.SetItems(entries)
+	if lastSelection >= len(entries) {
+		lastSelection = len(entries) - 1
+	}
+	file.SetCurrentIndex(lastSelection)
+
+	slog.Printf("Saved %s\n", current.Name())
+}
+
+func onFileSaveAs() {
+	if current == nil {
+		popup.Errorf(mw, "file save as: nothing to save")
 		return
 	}
 
-	isEdited = false
-	fileName := filepath.Base(archivePath)
-	mw.SetTitle(fileName)
+	path, err := popup.Save(mw, "Save EQ Archive As", archiveFilter, filepath.Dir(current.Path()), current.Name())
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(mw, "save as: %s", err)
+		return
+	}
 
-	lastSelection := file.CurrentIndex()
+	ext := strings.ToLower(filepath.Ext(path))
+	if !session.IsArchiveExt(ext) {
+		popup.Errorf(mw, "save as: unsupported archive extension %s", ext)
+		return
+	}
 
-	entries := []*component.FileViewEntry{}
-	files := archive.Files()
+	t := tabByPath(path)
+	if t != nil && t != activeTab {
+		popup.Errorf(mw, "save as: %s is open in another tab", filepath.Base(path))
+		return
+	}
 
-	for _, fe := range files {
-		ext := strings.ToLower(filepath.Ext(fe.Name()))
-		img, err := ico.Generate(ext, fe.Data())
-		if err != nil {
-			slog.Printf("Failed to generate icon for %s: %s\n", fe.Name(), err.Error())
-			img = ico.Grab("unk")
+	exclude := []string{}
+	incompatible := current.Incompatible(ext)
+	if len(incompatible) > 0 {
+		msg := fmt.Sprintf("%d entries are not used by %s archives:\n\n%s\n\nRemove them from %s?", len(incompatible), ext, strings.Join(incompatible, "\n"), filepath.Base(path))
+		switch popup.MessageBoxYesNoCancel(mw, "Incompatible entries", msg) {
+		case walk.DlgCmdYes:
+			exclude = incompatible
+		case walk.DlgCmdNo:
+		default:
+			return
 		}
+	}
+
+	err = current.SaveAs(path, exclude)
+	if err != nil {
+		popup.Errorf(mw, "save as: %s", err)
+		return
+	}
+
+	fileView.SetItems(fileViewEntries())
+	updateJumps()
+	updateTitle()
+	addRecent(path)
+
+	slog.Printf("Saved %s\n", current.Name())
+}
+
+func onFileRestoreBackup() {
+	if current == nil {
+		popup.Errorf(mw, "restore backup: no archive is open")
+		return
+	}
+
+	backups, err := current.Backups()
+	if err != nil {
+		popup.Errorf(mw, "restore backup: %s", err)
+		return
+	}
+	if len(backups) == 0 {
+		popup.MessageBox(mw, "Restore Backup", "No backups found for "+current.Name(), false)
+		return
+	}
 
-		fve := &component.FileViewEntry{
-			Icon:    img,
-			Name:    fe.Name(),
-			Ext:     ext,
-			Size:    generateSize(len(fe.Data())),
-			RawSize: len(fe.Data()),
+	path, err := dialog.ShowRestoreBackup(mw, current.Name(), backups)
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
 		}
+		popup.Errorf(mw, "restore backup: %s", err)
+		return
+	}
 
-		entries = append(entries, fve)
+	msg := fmt.Sprintf("Replace %s with %s?", current.Name(), filepath.Base(path))
+	if current.IsDirty() {
+		msg += "\n\nUnsaved changes will be lost."
 	}
-	fileView.SetItems(entries)
-	if lastSelection >= len(entries) {
-		lastSelection = len(entries) - 1
+	if !popup.MessageBoxYesNo(mw, "Restore Backup", msg) {
+		return
 	}
-	file.SetCurrentIndex(lastSelection)
 
-	slog.Printf("Saved %s\n", fileName)
-}
+	err = current.RestoreBackup(path)
+	if err != nil {
+		popup.Errorf(mw, "restore backup: %s", err)
+		return
+	}
 
-func onFileSaveAs() {
-	slog.Println("save as triggered")
+	fileView.SetItems(fileViewEntries())
+	updateJumps()
+	updateTitle()
+
+	slog.Printf("Restored %s from %s\n", current.Name(), filepath.Base(path))
 }
 
 func onFileExit() {
 	slog.Println("File Exit triggered")
+	if !confirmDiscardAll() {
+		return
+	}
+	isExitConfirmed = true
 	err := mw.Close()
 	if err != nil {
 		popup.Errorf(mw, "close: %s", err)
@@ -131,19 +267,19 @@ func onFileExit() {
 }
 
 func onFileClose() {
-	if archive == nil {
+	if activeTab == nil {
 		slog.Println("No archive to close")
 		return
 	}
-	err := archive.Close()
+	if !confirmDiscard(activeTab) {
+		return
+	}
+	name := activeTab.sess.Name()
+	err := closeTab(activeTab)
 	if err != nil {
-		popup.Errorf(mw, "archive close: %s", err)
+		popup.Errorf(mw, "close: %s", err)
+		return
 	}
-	archive = nil
-	archivePath = ""
-
-	fileView.ResetRows()
-	entrySetActive(false)
 
-	slog.Printf("Closed %s\n", archivePath)
+	slog.Printf("Closed %s\n", name)
 }
@@ -0,0 +1,89 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/xackery/wlk/walk"
+)
+
+var (
+	menuEditFind *walk.Action
+)
+
+// onFilterChanged applies the filter box of the active tab to its table
+func onFilterChanged() {
+	if activeTab == nil || activeTab.filter == nil {
+		return
+	}
+	err := fileView.SetFilter(activeTab.filter.Text())
+	if err != nil {
+		logf("Invalid filter: %s", err)
+		return
+	}
+	entrySetActive(file.CurrentIndex() >= 0)
+	updateFilterStatus()
+}
+
+// updateFilterStatus shows how many entries match the filter of the active tab
+func updateFilterStatus() {
+	if fileView == nil || !fileView.IsFiltered() {
+		return
+	}
+	logf("%d of %d entries match", fileView.RowCount(), fileView.TotalCount())
+}
+
+// onEditFind focuses the filter box of the active tab
+func onEditFind() {
+	if activeTab == nil || activeTab.filter == nil {
+		return
+	}
+	err := activeTab.filter.SetFocus()
+	if err != nil {
+		logf("Failed to focus filter: %s", err)
+		return
+	}
+	activeTab.filter.SetTextSelection(0, -1)
+}
+
+// onFilterKeyDown clears the filter on escape
+func onFilterKeyDown(key walk.Key) {
+	if key != walk.KeyEscape || activeTab == nil {
+		return
+	}
+	err := activeTab.filter.SetText("")
+	if err != nil {
+		logf("Failed to clear filter: %s", err)
+	}
+}
+
+// tableShortcut is a menu action whose keys also edit text. It is bound to each
+// entry table instead of the window, so the same keys pressed in the filter box
+// edit the filter rather than the archive
+type tableShortcut struct {
+	shortcut    walk.Shortcut
+	onTriggered func()
+}
+
+// addTableShortcuts binds the table shortcuts to an entry table
+func addTableShortcuts(table *walk.TableView) error {
+	tableShortcuts := []tableShortcut{
+		{walk.Shortcut{Key: walk.KeyDelete}, onMenuEntryDelete},
+		{walk.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyX}, onFileClose},
+		{walk.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyA}, onMenuEntrySelectAll},
+		{walk.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyZ}, onEditUndo},
+		{walk.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyY}, onEditRedo},
+	}
+	for _, ts := range tableShortcuts {
+		action := walk.NewAction()
+		err := action.SetShortcut(ts.shortcut)
+		if err != nil {
+			return fmt.Errorf("set shortcut %s: %w", ts.shortcut, err)
+		}
+		action.Triggered().Attach(ts.onTriggered)
+		err = table.ShortcutActions().Add(action)
+		if err != nil {
+			return fmt.Errorf("add shortcut %s: %w", ts.shortcut, err)
+		}
+	}
+	return nil
+}
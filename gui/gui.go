@@ -8,20 +8,18 @@ import (
 
 	"github.com/xackery/quail-gui/gui/component"
 	"github.com/xackery/quail-gui/ico"
+	"github.com/xackery/quail-gui/session"
 	"github.com/xackery/quail-gui/slog"
-	"github.com/xackery/quail/pfs"
 	"github.com/xackery/wlk/cpl"
 	"github.com/xackery/wlk/walk"
 )
 
 var (
-	mw          *walk.MainWindow
-	statusBar   *walk.StatusBarItem
-	isEdited    bool
-	archive     *pfs.Pfs // currently loaded pfs archive
-	archivePath string   // used for writing archive back to file
-	file        *walk.TableView
-	fileView    *component.FileView
+	mw        *walk.MainWindow
+	statusBar *walk.StatusBarItem
+	current   *session.Session    // archive of the active tab
+	file      *walk.TableView     // table of the active tab
+	fileView  *component.FileView // model of the active tab
 )
 
 func New() error {
@@ -31,9 +29,6 @@ func New() error {
 
 	slog.AddHandler(logf)
 
-	fileView = component.NewFileView()
-	fvs := component.NewFileViewStyler(fileView)
-
 	cmw := cpl.MainWindow{
 		AssignTo:      &mw,
 		MinSize:       cpl.Size{Width: 400, Height: 300},
@@ -47,32 +42,52 @@ func New() error {
 					cpl.Action{Text: " &New", AssignTo: &menuFileNew, OnTriggered: onFileNew},
 					cpl.Separator{},
 					cpl.Action{Text: "&Open", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyO}, AssignTo: &menuFileOpen, OnTriggered: onFileOpen},
-					cpl.Action{Text: "Open &Recent", AssignTo: &menuFileOpenRecent, OnTriggered: onFileOpenRecent},
+					cpl.Menu{Text: "Open &Recent", AssignTo: &menuFileOpenRecent},
 					cpl.Separator{},
 					cpl.Action{Text: "&Save", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyS}, AssignTo: &menuFileSave, OnTriggered: onFileSave},
 					cpl.Action{Text: "Save As...", AssignTo: &menuFileSaveAs, OnTriggered: onFileSaveAs},
+					cpl.Action{Text: "Restore &Backup...", AssignTo: &menuFileRestoreBackup, OnTriggered: onFileRestoreBackup},
 					cpl.Separator{},
 					cpl.Action{Text: "&Refresh", Shortcut: cpl.Shortcut{Key: walk.KeyF5}, AssignTo: &menuFileRefresh, OnTriggered: onFileRefresh},
 
-					cpl.Action{Text: "&Close", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyX}, AssignTo: &menuFileClose, OnTriggered: onFileClose},
+					cpl.Action{Text: "&Close\tCtrl+X", AssignTo: &menuFileClose, OnTriggered: onFileClose},
 					cpl.Action{Text: "E&xit", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyQ}, AssignTo: &menuFileExit, OnTriggered: onFileExit},
 				},
 			},
 			cpl.Menu{
 				Text: "&Edit",
 				Items: []cpl.MenuItem{
+					cpl.Action{Text: "&Undo\tCtrl+Z", AssignTo: &menuEditUndo, OnTriggered: onEditUndo},
+					cpl.Action{Text: "&Redo\tCtrl+Y", AssignTo: &menuEditRedo, OnTriggered: onEditRedo},
+					cpl.Action{Text: "&History...", AssignTo: &menuEditHistory, OnTriggered: onEditHistory},
+					cpl.Separator{},
+					cpl.Action{Text: "&Find...", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyF}, AssignTo: &menuEditFind, OnTriggered: onEditFind},
+					cpl.Action{Text: "Select &All\tCtrl+A", AssignTo: &menuEntrySelectAll, OnTriggered: onMenuEntrySelectAll},
+					cpl.Separator{},
 					cpl.Action{Text: "&Preferences", AssignTo: &menuEditPreferences, OnTriggered: onEditPreferences},
 				},
 			},
+			cpl.Menu{
+				Text: "&View",
+				Items: []cpl.MenuItem{
+					cpl.Action{Text: "&Preview Pane", Shortcut: cpl.Shortcut{Key: walk.KeyF3}, Checkable: true, Checked: true, AssignTo: &menuViewPreview, OnTriggered: onViewPreview},
+				},
+			},
 			cpl.Menu{
 				Text: "&Entry",
 				Items: []cpl.MenuItem{
 					cpl.Action{Text: " &New", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyN}, AssignTo: &menuEntryNew, OnTriggered: onMenuEntryNew},
+					cpl.Action{Text: " &Import...", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyI}, AssignTo: &menuEntryImport, OnTriggered: onMenuEntryImport},
 					cpl.Separator{},
 					cpl.Action{Text: " &Edit", AssignTo: &menuEntryEdit, OnTriggered: onMenuEntryEdit},
-					cpl.Action{Text: " &Delete", Shortcut: cpl.Shortcut{Key: walk.KeyDelete}, AssignTo: &menuEntryDelete, OnTriggered: onMenuEntryDelete},
+					cpl.Action{Text: " &Delete\tDelete", AssignTo: &menuEntryDelete, OnTriggered: onMenuEntryDelete},
 					cpl.Separator{},
-					cpl.Action{Text: " &Rename", AssignTo: &menuEntryRename, OnTriggered: onMenuEntryRename},
+					cpl.Action{Text: " &Rename", Shortcut: cpl.Shortcut{Key: walk.KeyF2}, AssignTo: &menuEntryRename, OnTriggered: onMenuEntryRename},
+					cpl.Action{Text: " &Bulk Rename...", Shortcut: cpl.Shortcut{Modifiers: walk.ModShift, Key: walk.KeyF2}, AssignTo: &menuEntryBulkRename, OnTriggered: onMenuEntryBulkRename},
+					cpl.Action{Text: " Con&vert Texture...", AssignTo: &menuEntryConvertTexture, OnTriggered: onMenuEntryConvertTexture},
+					cpl.Separator{},
+					cpl.Action{Text: " E&xtract...", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl, Key: walk.KeyE}, AssignTo: &menuEntryExtract, OnTriggered: onMenuEntryExtract},
+					cpl.Action{Text: " Extract &All...", Shortcut: cpl.Shortcut{Modifiers: walk.ModControl | walk.ModShift, Key: walk.KeyE}, AssignTo: &menuEntryExtractAll, OnTriggered: onMenuEntryExtractAll},
 				},
 			},
 			cpl.Menu{
@@ -108,30 +123,33 @@ func New() error {
 		OnDropFiles: onDrop,
 		Layout:      cpl.VBox{},
 		Children: []cpl.Widget{
-			cpl.TableView{
-				AssignTo:         &file,
-				AlternatingRowBG: true,
-				ColumnsOrderable: true,
-				MultiSelection:   false,
-				OnKeyDown: func(key walk.Key) {
-					if key == walk.KeyUp || key == walk.KeyDown {
-						onEntryChange()
-					}
-				},
-				OnCurrentIndexChanged: onEntryChange,
-				OnItemActivated:       onEntryActivate,
-				StyleCell:             fvs.StyleCell,
-				Model:                 fileView,
-				ContextMenuItems: []cpl.MenuItem{
-					cpl.Action{Text: "Refresh", Image: ico.Grab("refresh"), AssignTo: &menuFileRefresh, OnTriggered: onFileRefresh},
-					cpl.Separator{},
-					cpl.Action{Text: "Delete", Image: ico.Grab("delete"), AssignTo: &menuFileDelete, OnTriggered: onFileDelete},
-				},
-				//MaxSize:               cpl.Size{Width: 300, Height: 0},
-				Columns: []cpl.TableViewColumn{
-					{Name: "Name", Width: 160},
-					{Name: "Ext", Width: 40},
-					{Name: "Size", Width: 80},
+			cpl.HSplitter{
+				Children: []cpl.Widget{
+					cpl.TabWidget{
+						AssignTo:              &tabWidget,
+						OnCurrentIndexChanged: onTabChange,
+						StretchFactor:         2,
+					},
+					cpl.Composite{
+						AssignTo:      &previewPane,
+						Layout:        cpl.VBox{MarginsZero: true},
+						StretchFactor: 1,
+						Children: []cpl.Widget{
+							cpl.Label{AssignTo: &previewTitle},
+							cpl.ImageView{
+								AssignTo: &previewImage,
+								Mode:     cpl.ImageViewModeShrink,
+								Visible:  false,
+							},
+							cpl.TextEdit{
+								AssignTo: &previewText,
+								ReadOnly: true,
+								VScroll:  true,
+								HScroll:  true,
+								Font:     cpl.Font{Family: "Consolas", PointSize: 9},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -150,7 +168,15 @@ func New() error {
 		return fmt.Errorf("create main window: %w", err)
 	}
 
+	mw.Closing().Attach(onClosing)
+
+	pruneRecent()
+	rebuildRecentMenu()
+	updateHistoryMenu()
 	entrySetActive(false)
+	setJumpWorldEnabled(false)
+	setJumpLightEnabled(false)
+	setJumpObjectEnabled(false)
 
 	return nil
 }
@@ -184,6 +210,9 @@ func logf(format string, a ...interface{}) {
 		line = "  " + line[0:strings.Index(line, "\n")]
 	}
 	statusBar.SetText(line)
+	if activeTab != nil {
+		activeTab.status = line
+	}
 }
 
 func generateSize(in int) string {
@@ -0,0 +1,107 @@
+package gui
+
+import (
+	"github.com/xackery/quail-gui/gui/dialog"
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/wlk/walk"
+)
+
+var (
+	menuEditUndo    *walk.Action
+	menuEditRedo    *walk.Action
+	menuEditHistory *walk.Action
+)
+
+func onEditUndo() {
+	if current == nil || !current.CanUndo() {
+		return
+	}
+	title := current.UndoTitle()
+	err := current.Undo()
+	if err != nil {
+		popup.Errorf(mw, "undo: %s", err)
+		refreshHistory()
+		return
+	}
+	refreshHistory()
+	slog.Printf("Undid %s\n", title)
+}
+
+func onEditRedo() {
+	if current == nil || !current.CanRedo() {
+		return
+	}
+	title := current.RedoTitle()
+	err := current.Redo()
+	if err != nil {
+		popup.Errorf(mw, "redo: %s", err)
+		refreshHistory()
+		return
+	}
+	refreshHistory()
+	slog.Printf("Redid %s\n", title)
+}
+
+func onEditHistory() {
+	if current == nil {
+		return
+	}
+	done, undone := current.History()
+	target, err := dialog.ShowHistory(mw, current.Name(), done, undone)
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(mw, "history: %s", err)
+		return
+	}
+
+	for i := len(done); i > target; i-- {
+		err = current.Undo()
+		if err != nil {
+			popup.Errorf(mw, "history: %s", err)
+			break
+		}
+	}
+	for i := len(done); i < target; i++ {
+		err = current.Redo()
+		if err != nil {
+			popup.Errorf(mw, "history: %s", err)
+			break
+		}
+	}
+	refreshHistory()
+}
+
+// refreshHistory redraws the active archive after its entries changed through undo or redo
+func refreshHistory() {
+	if fileView == nil {
+		return
+	}
+	refreshEntries()
+	updateJumps()
+	updateTitle()
+}
+
+// updateHistoryMenu names the change undo and redo would apply
+func updateHistoryMenu() {
+	if menuEditUndo == nil {
+		return
+	}
+	undoText := "&Undo"
+	redoText := "&Redo"
+	isUndo := current != nil && current.CanUndo()
+	isRedo := current != nil && current.CanRedo()
+	if isUndo {
+		undoText += " " + current.UndoTitle()
+	}
+	if isRedo {
+		redoText += " " + current.RedoTitle()
+	}
+	menuEditUndo.SetText(undoText)
+	menuEditUndo.SetEnabled(isUndo)
+	menuEditRedo.SetText(redoText)
+	menuEditRedo.SetEnabled(isRedo)
+	menuEditHistory.SetEnabled(current != nil)
+}
@@ -0,0 +1,140 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-gui/gui/dialog"
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/wlk/walk"
+)
+
+var (
+	menuEntryImport *walk.Action
+)
+
+func onMenuEntryImport() {
+	if current == nil {
+		popup.Errorf(mw, "import: no archive is open")
+		return
+	}
+	paths, err := popup.OpenMultiple(mw, "Import into "+current.Name(), "All Files (*.*)|*.*|Textures (*.dds;*.bmp;*.png)|*.dds;*.bmp;*.png|Models (*.mod;*.mds;*.ter;*.zon)|*.mod;*.mds;*.ter;*.zon|Text (*.txt)|*.txt", ".")
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return
+		}
+		popup.Errorf(mw, "import: %s", err)
+		return
+	}
+	importFiles(paths)
+}
+
+// importFiles adds files from disk to the active archive, asking how to resolve
+// name conflicts
+func importFiles(paths []string) {
+	if current == nil {
+		popup.Errorf(mw, "import: open an archive before importing files")
+		return
+	}
+
+	applyAll := false
+	resolution := dialog.ConflictSkip
+	added, replaced, skipped := 0, 0, 0
+
+	// one undo step for the whole import, not one per file
+	title := fmt.Sprintf("Import %d files", len(paths))
+	if len(paths) == 1 {
+		title = "Import " + strings.ToLower(filepath.Base(paths[0]))
+	}
+	err := current.Group(title, func() error {
+		for i, path := range paths {
+			name := strings.ToLower(filepath.Base(path))
+			data, err := os.ReadFile(path)
+			if err != nil {
+				popup.Errorf(mw, "import %s: %s", name, err)
+				continue
+			}
+
+			if !current.Has(name) {
+				err = current.Add(name, data)
+				if err != nil {
+					popup.Errorf(mw, "import %s: %s", name, err)
+					continue
+				}
+				added++
+				continue
+			}
+
+			newName := current.UniqueName(name)
+			if !applyAll {
+				resolution, newName, applyAll, err = dialog.ShowImportConflict(mw, name, newName, remainingConflicts(paths, i))
+				if err != nil {
+					if err.Error() == "cancelled" {
+						break
+					}
+					popup.Errorf(mw, "import conflict: %s", err)
+					break
+				}
+			}
+
+			switch resolution {
+			case dialog.ConflictReplace:
+				err = current.Replace(name, data)
+				if err != nil {
+					popup.Errorf(mw, "import %s: %s", name, err)
+					continue
+				}
+				replaced++
+			case dialog.ConflictRename:
+				if current.Has(newName) {
+					newName = current.UniqueName(newName)
+				}
+				err = current.Add(strings.ToLower(newName), data)
+				if err != nil {
+					popup.Errorf(mw, "import %s: %s", name, err)
+					continue
+				}
+				added++
+			default:
+				skipped++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		popup.Errorf(mw, "import: %s", err)
+	}
+
+	if added+replaced == 0 {
+		slog.Printf("Imported nothing, skipped %d\n", skipped)
+		return
+	}
+
+	refreshEntries()
+	updateJumps()
+	updateTitle()
+	slog.Printf("Imported %d new, %d replaced, %d skipped\n", added, replaced, skipped)
+}
+
+// remainingConflicts counts the files after paths[i] whose names are already
+// in the archive or used earlier in the same import
+func remainingConflicts(paths []string, i int) int {
+	count := 0
+	for j := i + 1; j < len(paths); j++ {
+		name := filepath.Base(paths[j])
+		if current.Has(name) {
+			count++
+			continue
+		}
+		for _, prev := range paths[i:j] {
+			if strings.EqualFold(filepath.Base(prev), name) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
@@ -32,8 +32,38 @@ func setJumpObjectEnabled(enabled bool) {
 	toolbarJumpToObject.SetEnabled(enabled)
 }
 
+// updateJumps enables jump actions based on the wld and zon entries of the active archive
+func updateJumps() {
+	setJumpLightEnabled(false)
+	setJumpObjectEnabled(false)
+	setJumpWorldEnabled(false)
+	if current == nil {
+		return
+	}
+
+	for _, fe := range current.Files() {
+		ext := strings.ToLower(filepath.Ext(fe.Name()))
+		if ext != ".wld" && ext != ".zon" {
+			continue
+		}
+		baseName := strings.ToLower(filepath.Base(fe.Name()))
+		baseName = strings.ReplaceAll(baseName, ".wld", "")
+		baseName = strings.ReplaceAll(baseName, ".zon", "")
+		if baseName == "objects" {
+			setJumpObjectEnabled(true)
+		} else if baseName == "lights" {
+			setJumpLightEnabled(true)
+		} else {
+			setJumpWorldEnabled(true)
+		}
+	}
+}
+
 func onMenuJumpToWorld() {
-	wldName := filepath.Base(archivePath)
+	if current == nil {
+		return
+	}
+	wldName := current.Name()
 	// replace ext with .wld
 	wldName = strings.ReplaceAll(wldName, filepath.Ext(wldName), ".wld")
 	idx, item := fileView.ItemByName(wldName)
@@ -70,6 +100,9 @@ func onMenuJumpToWorld() {
 }
 
 func onMenuJumpToLight() {
+	if current == nil {
+		return
+	}
 	idx, item := fileView.ItemByName("lights.wld")
 	if item != nil {
 		file.SetCurrentIndex(idx)
@@ -82,6 +115,9 @@ func onMenuJumpToLight() {
 }
 
 func onMenuJumpToObject() {
+	if current == nil {
+		return
+	}
 	idx, item := fileView.ItemByName("objects.wld")
 	if item != nil {
 		file.SetCurrentIndex(idx)
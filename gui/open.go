@@ -8,9 +8,10 @@ import (
 
 	"github.com/xackery/quail-gui/gui/component"
 	"github.com/xackery/quail-gui/ico"
+	"github.com/xackery/quail-gui/session"
 	"github.com/xackery/quail-gui/slog"
-	"github.com/xackery/quail/pfs"
 	"github.com/xackery/quail/raw"
+	"github.com/xackery/wlk/walk"
 )
 
 func Open(path string) error {
@@ -24,12 +25,6 @@ func Open(path string) error {
 
 	slog.Printf("Opening path: %s\n", path)
 
-	r, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer r.Close()
-
 	isPFS := false
 	ext := filepath.Ext(strings.ToLower(path))
 
@@ -45,6 +40,12 @@ func Open(path string) error {
 	}
 
 	if !isPFS {
+		r, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
 		name := filepath.Base(path)
 		ext := strings.ToLower(filepath.Ext(name))
 		value, err := raw.Read(ext, r)
@@ -56,66 +57,209 @@ func Open(path string) error {
 		return nil
 	}
 
-	archive, err = pfs.New(filepath.Base(path))
-	if err != nil {
-		return fmt.Errorf("pfs.New: %w", err)
+	t := tabByPath(path)
+	if t != nil && !confirmDiscard(t) {
+		return fmt.Errorf("cancelled")
 	}
 
-	err = archive.Read(r)
+	sess, err := session.Open(path)
 	if err != nil {
-		archive = nil
-		return fmt.Errorf("decode: %w", err)
+		return err
 	}
 
-	archivePath = path
-
-	setJumpLightEnabled(false)
-	setJumpObjectEnabled(false)
-	setJumpWorldEnabled(false)
-
-	entries := []*component.FileViewEntry{}
-	files := archive.Files()
-	for _, fe := range files {
-		ext := strings.ToLower(filepath.Ext(fe.Name()))
-		img, err := ico.Generate(ext, fe.Data())
+	if t != nil {
+		err = t.sess.Close()
 		if err != nil {
-			slog.Printf("Failed to generate icon for %s: %s\n", fe.Name(), err.Error())
-			img = ico.Grab("unk")
+			slog.Printf("Failed to close %s: %s\n", t.sess.Name(), err.Error())
 		}
-
-		fve := &component.FileViewEntry{
-			Icon:    img,
-			Name:    fe.Name(),
-			Ext:     ext,
-			Size:    generateSize(len(fe.Data())),
-			RawSize: len(fe.Data()),
-		}
-		if ext == ".wld" || ext == ".zon" {
-			baseName := strings.ToLower(filepath.Base(fe.Name()))
-			baseName = strings.ReplaceAll(baseName, ".wld", "")
-			baseName = strings.ReplaceAll(baseName, ".zon", "")
-			if baseName == "objects" {
-				setJumpObjectEnabled(true)
-			} else if baseName == "lights" {
-				setJumpLightEnabled(true)
-			} else {
-				setJumpWorldEnabled(true)
-			}
+		t.sess = sess
+	} else {
+		t, err = newTab(sess)
+		if err != nil {
+			sess.Close()
+			return err
 		}
-
-		entries = append(entries, fve)
 	}
+	setActiveTab(t)
+
+	entries := fileViewEntries()
 	fileView.SetItems(entries)
 	file.SetLastColumnStretched(true)
 	slog.Printf("Loaded %d files\n", len(entries))
+	updateFilterStatus()
 	if len(entries) > 0 {
 		file.SetCurrentIndex(0)
 		entrySetActive(true)
 	}
 
-	fileName := filepath.Base(path)
-
-	mw.SetTitle(fileName)
+	updateJumps()
+	updateTitle()
+	updatePreview()
+	addRecent(path)
 
 	return nil
 }
+
+// iconKey identifies entry contents so an icon is only generated once per edit
+type iconKey struct {
+	ext  string
+	crc  uint32
+	size int
+}
+
+// ratioKey identifies an entry whose ratio is being measured
+type ratioKey struct {
+	sess *session.Session
+	name string
+	crc  uint32
+}
+
+// pendingRatio is a row whose ratio is measured in the background
+type pendingRatio struct {
+	entry *component.FileViewEntry
+	key   ratioKey
+	data  []byte
+}
+
+var measuring = map[ratioKey]bool{}
+
+// fileViewEntries builds a row for every entry in the current archive
+func fileViewEntries() []*component.FileViewEntry {
+	entries := []*component.FileViewEntry{}
+	if current == nil || activeTab == nil {
+		return entries
+	}
+
+	// the tab only keeps the icons of its current entries
+	icons := map[iconKey]*walk.Icon{}
+	pending := []pendingRatio{}
+	for _, info := range current.Infos() {
+		entry, err := newFileViewEntry(info, icons)
+		if err != nil {
+			slog.Printf("Failed to read %s: %s\n", info.Name, err.Error())
+			continue
+		}
+		entries = append(entries, entry)
+
+		key := ratioKey{sess: current, name: strings.ToLower(info.Name), crc: info.CRC}
+		if info.CompressedSize >= 0 || measuring[key] {
+			continue
+		}
+		data, err := current.File(info.Name)
+		if err != nil {
+			continue
+		}
+		measuring[key] = true
+		pending = append(pending, pendingRatio{entry: entry, key: key, data: data})
+	}
+	disposeIcons(activeTab.icons, icons)
+	activeTab.icons = icons
+	measureRatios(fileView, pending)
+	return entries
+}
+
+// measureRatios compresses the entries of rows off the UI thread and fills in
+// their ratio, compressing a large archive takes seconds
+func measureRatios(view *component.FileView, pending []pendingRatio) {
+	if len(pending) == 0 {
+		return
+	}
+	go func() {
+		sizes := make([]int, len(pending))
+		for i, p := range pending {
+			sizes[i] = session.CompressedSize(p.data)
+		}
+		mw.Synchronize(func() {
+			ratios := map[*component.FileViewEntry]float64{}
+			for i, p := range pending {
+				delete(measuring, p.key)
+				if tabBySession(p.key.sess) == nil {
+					continue
+				}
+				p.key.sess.SetCompressedSize(p.key.name, p.key.crc, sizes[i])
+				info, err := p.key.sess.Info(p.key.name)
+				if err != nil || info.CRC != p.key.crc {
+					continue
+				}
+				ratios[p.entry] = info.Ratio()
+			}
+			view.SetRatios(ratios)
+		})
+	}()
+}
+
+// newFileViewEntry builds the row of an entry in the current archive, adding
+// its icon to icons
+func newFileViewEntry(info *session.Info, icons map[iconKey]*walk.Icon) (*component.FileViewEntry, error) {
+	key := iconKey{ext: info.Ext, crc: info.CRC, size: info.Size}
+	img, ok := icons[key]
+	if !ok {
+		img, ok = activeTab.icons[key]
+	}
+	if !ok {
+		data, err := current.File(info.Name)
+		if err != nil {
+			return nil, err
+		}
+		img, err = ico.Generate(info.Ext, data)
+		if err != nil {
+			slog.Printf("Failed to generate icon for %s: %s\n", info.Name, err.Error())
+			img = ico.Grab("unk")
+		}
+	}
+	icons[key] = img
+
+	rowName := info.Name
+	if info.IsEdited {
+		rowName += "*"
+	}
+	return &component.FileViewEntry{
+		Icon:     img,
+		Name:     rowName,
+		Ext:      info.Ext,
+		Size:     generateSize(info.Size),
+		RawSize:  info.Size,
+		CRC:      info.CRC,
+		Ratio:    info.Ratio(),
+		Type:     info.Type,
+		IsEdited: info.IsEdited,
+	}, nil
+}
+
+// disposeIcons releases the generated icons of old that keep doesn't hold.
+// Icons shared by an extension belong to ico and are left alone
+func disposeIcons(old map[iconKey]*walk.Icon, keep map[iconKey]*walk.Icon) {
+	for key, icon := range old {
+		if keep[key] == icon {
+			continue
+		}
+		if icon == ico.Grab(strings.TrimPrefix(key.ext, ".")) || icon == ico.Grab("unk") {
+			continue
+		}
+		icon.Dispose()
+	}
+}
+
+// updateTitle shows the active archive name, with a * when it has unsaved changes,
+// and refreshes the undo and redo menu items
+func updateTitle() {
+	updateHistoryMenu()
+	if current == nil {
+		mw.SetTitle("quail-gui")
+		return
+	}
+	title := current.Name()
+	if current.IsDirty() {
+		title += "*"
+	}
+	err := mw.SetTitle(title)
+	if err != nil {
+		slog.Printf("Failed to set title: %s\n", err.Error())
+	}
+	if activeTab != nil {
+		err = activeTab.page.SetTitle(title)
+		if err != nil {
+			slog.Printf("Failed to set tab title: %s\n", err.Error())
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gui
+
+import (
+	"image"
+	"strings"
+
+	"github.com/xackery/quail-gui/session"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/wlk/walk"
+)
+
+var (
+	previewPane     *walk.Composite
+	previewTitle    *walk.Label
+	previewImage    *walk.ImageView
+	previewText     *walk.TextEdit
+	previewBitmap   *walk.Bitmap
+	menuViewPreview *walk.Action
+	previewSeq      int // bumped on every update so a slow decode can't replace a newer preview
+)
+
+func onViewPreview() {
+	isVisible := !previewPane.Visible()
+	previewPane.SetVisible(isVisible)
+	menuViewPreview.SetChecked(isVisible)
+	updatePreview()
+}
+
+// updatePreview shows the current entry of the active tab in the preview pane
+func updatePreview() {
+	if previewPane == nil || !previewPane.Visible() {
+		return
+	}
+	previewSeq++
+	seq := previewSeq
+	if current == nil || file == nil || file.CurrentIndex() < 0 || file.CurrentIndex() >= fileView.RowCount() {
+		setPreview("", nil, "")
+		return
+	}
+	name := strings.ReplaceAll(fileView.Item(file.CurrentIndex()).Name, "*", "")
+
+	data, err := current.File(name)
+	if err != nil {
+		setPreview(name, nil, err.Error())
+		return
+	}
+	setPreview(name, nil, "Loading...")
+
+	// decode off the UI thread, large textures and wlds take a while
+	go func() {
+		preview, err := session.DecodePreview(name, data)
+		mw.Synchronize(func() {
+			if seq != previewSeq {
+				return
+			}
+			if err != nil {
+				setPreview(name, nil, err.Error())
+				return
+			}
+			setPreview(name, preview.Image, preview.Text)
+		})
+	}()
+}
+
+// setPreview replaces the contents of the preview pane, textures are shown above their summary
+func setPreview(title string, img image.Image, text string) {
+	previewTitle.SetText(title)
+
+	var bitmap *walk.Bitmap
+	if img != nil {
+		var err error
+		bitmap, err = walk.NewBitmapFromImageForDPI(img, 96)
+		if err != nil {
+			slog.Printf("Failed to create preview of %s: %s\n", title, err.Error())
+		}
+	}
+	err := previewImage.SetImage(bitmap)
+	if err != nil {
+		slog.Printf("Failed to set preview image: %s\n", err.Error())
+	}
+	if previewBitmap != nil {
+		previewBitmap.Dispose()
+	}
+	previewBitmap = bitmap
+	previewImage.SetVisible(bitmap != nil)
+
+	err = previewText.SetText(text)
+	if err != nil {
+		slog.Printf("Failed to set preview text: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,142 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xackery/quail-gui/config"
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/wlk/walk"
+)
+
+// addRecent records an opened archive in the config and refreshes Open Recent
+func addRecent(path string) {
+	cfg := config.Instance()
+	if cfg == nil {
+		return
+	}
+	cfg.AddRecent(absPath(path))
+	err := cfg.Save()
+	if err != nil {
+		slog.Printf("Failed to save recent files: %s\n", err.Error())
+	}
+	rebuildRecentMenu()
+}
+
+// rebuildRecentMenu repopulates Open Recent with pinned and recent archives
+func rebuildRecentMenu() {
+	if menuFileOpenRecent == nil {
+		return
+	}
+	cfg := config.Instance()
+	if cfg == nil {
+		return
+	}
+
+	actions := menuFileOpenRecent.Actions()
+	err := actions.Clear()
+	if err != nil {
+		slog.Printf("Failed to clear recent menu: %s\n", err.Error())
+		return
+	}
+
+	for _, path := range cfg.PinnedFiles {
+		_, err := os.Stat(path)
+		addRecentAction(actions, "* "+path, path, err == nil)
+	}
+	if len(cfg.PinnedFiles) > 0 && len(cfg.RecentFiles) > 0 {
+		actions.Add(walk.NewSeparatorAction())
+	}
+	for i, path := range cfg.RecentFiles {
+		if cfg.IsPinned(path) {
+			continue
+		}
+		addRecentAction(actions, fmt.Sprintf("&%d %s", (i+1)%10, path), path, true)
+	}
+	if len(cfg.PinnedFiles) > 0 || len(cfg.RecentFiles) > 0 {
+		actions.Add(walk.NewSeparatorAction())
+	}
+
+	pin := walk.NewAction()
+	pin.SetText("&Pin Current Archive")
+	pin.SetCheckable(true)
+	pin.SetChecked(current != nil && cfg.IsPinned(absPath(current.Path())))
+	pin.SetEnabled(current != nil)
+	pin.Triggered().Attach(onFileTogglePin)
+	actions.Add(pin)
+
+	clearAction := walk.NewAction()
+	clearAction.SetText("&Clear Recent")
+	clearAction.SetEnabled(len(cfg.RecentFiles) > 0)
+	clearAction.Triggered().Attach(onFileClearRecent)
+	actions.Add(clearAction)
+}
+
+// pruneRecent drops recent archives that no longer exist, it stats every entry
+// so is only run at startup and when a recent archive fails to open
+func pruneRecent() {
+	cfg := config.Instance()
+	if cfg == nil || !cfg.PruneRecent() {
+		return
+	}
+	err := cfg.Save()
+	if err != nil {
+		slog.Printf("Failed to save recent files: %s\n", err.Error())
+	}
+}
+
+func addRecentAction(actions *walk.ActionList, text string, path string, isEnabled bool) {
+	action := walk.NewAction()
+	action.SetText(text)
+	action.SetEnabled(isEnabled)
+	action.Triggered().Attach(func() {
+		err := Open(path)
+		if err != nil {
+			if err.Error() == "cancelled" {
+				return
+			}
+			popup.Errorf(mw, "open recent: %s", err)
+			pruneRecent()
+			rebuildRecentMenu()
+			return
+		}
+	})
+	actions.Add(action)
+}
+
+func onFileTogglePin() {
+	cfg := config.Instance()
+	if cfg == nil || current == nil {
+		return
+	}
+	path := absPath(current.Path())
+	cfg.SetPinned(path, !cfg.IsPinned(path))
+	err := cfg.Save()
+	if err != nil {
+		popup.Errorf(mw, "save config: %s", err)
+	}
+	rebuildRecentMenu()
+}
+
+func onFileClearRecent() {
+	cfg := config.Instance()
+	if cfg == nil {
+		return
+	}
+	cfg.ClearRecent()
+	err := cfg.Save()
+	if err != nil {
+		popup.Errorf(mw, "save config: %s", err)
+	}
+	rebuildRecentMenu()
+}
+
+func absPath(path string) string {
+	fullPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return fullPath
+}
@@ -0,0 +1,241 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-gui/gui/component"
+	"github.com/xackery/quail-gui/ico"
+	"github.com/xackery/quail-gui/session"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/wlk/cpl"
+	"github.com/xackery/wlk/walk"
+)
+
+// archiveTab is an open archive shown as a page in the main window
+type archiveTab struct {
+	sess     *session.Session
+	page     *walk.TabPage
+	table    *walk.TableView
+	fileView *component.FileView
+	filter   *walk.LineEdit
+	status   string                 // last status bar text while this tab was active
+	icons    map[iconKey]*walk.Icon // icons of the current rows, see fileViewEntries
+}
+
+var (
+	tabWidget *walk.TabWidget
+	tabs      []*archiveTab
+	activeTab *archiveTab
+)
+
+// newTab creates a page for an archive and adds it to the tab strip
+func newTab(sess *session.Session) (*archiveTab, error) {
+	t := &archiveTab{
+		sess:     sess,
+		fileView: component.NewFileView(),
+		status:   "Ready",
+	}
+	fvs := component.NewFileViewStyler(t.fileView)
+
+	page, err := walk.NewTabPage()
+	if err != nil {
+		return nil, fmt.Errorf("new tab page: %w", err)
+	}
+	err = page.SetTitle(sess.Name())
+	if err != nil {
+		return nil, fmt.Errorf("set tab title: %w", err)
+	}
+	err = page.SetLayout(walk.NewVBoxLayout())
+	if err != nil {
+		return nil, fmt.Errorf("set tab layout: %w", err)
+	}
+	err = tabWidget.Pages().Add(page)
+	if err != nil {
+		return nil, fmt.Errorf("add tab page: %w", err)
+	}
+	t.page = page
+
+	le := cpl.LineEdit{
+		AssignTo:      &t.filter,
+		CueBanner:     "Filter: name, *.dds, ext:wld, size>1MB",
+		OnTextChanged: onFilterChanged,
+		OnKeyDown:     onFilterKeyDown,
+	}
+	err = le.Create(cpl.NewBuilder(page))
+	if err != nil {
+		tabWidget.Pages().Remove(page)
+		page.Dispose()
+		return nil, fmt.Errorf("create filter: %w", err)
+	}
+
+	tv := cpl.TableView{
+		AssignTo:         &t.table,
+		AlternatingRowBG: true,
+		ColumnsOrderable: true,
+		MultiSelection:   true,
+		OnKeyDown: func(key walk.Key) {
+			if key == walk.KeyUp || key == walk.KeyDown {
+				onEntryChange()
+			}
+		},
+		OnCurrentIndexChanged: onEntryChange,
+		OnItemActivated:       onEntryActivate,
+		StyleCell:             fvs.StyleCell,
+		Model:                 t.fileView,
+		ContextMenuItems: []cpl.MenuItem{
+			cpl.Action{Text: "Refresh", Image: ico.Grab("refresh"), OnTriggered: onFileRefresh},
+			cpl.Separator{},
+			cpl.Action{Text: "Extract...", OnTriggered: onMenuEntryExtract},
+			cpl.Action{Text: "Convert Texture...", OnTriggered: onMenuEntryConvertTexture},
+			cpl.Separator{},
+			cpl.Action{Text: "Delete", Image: ico.Grab("delete"), OnTriggered: onFileDelete},
+		},
+		Columns: []cpl.TableViewColumn{
+			{Name: "Name", Width: 160},
+			{Name: "Ext", Width: 40},
+			{Name: "Size", Width: 80},
+			{Name: "CRC", Width: 70},
+			{Name: "Ratio", Width: 50},
+			{Name: "Type", Width: 60},
+			{Name: "Modified", Width: 60},
+		},
+	}
+	err = tv.Create(cpl.NewBuilder(page))
+	if err != nil {
+		tabWidget.Pages().Remove(page)
+		page.Dispose()
+		return nil, fmt.Errorf("create table: %w", err)
+	}
+	err = addTableShortcuts(t.table)
+	if err != nil {
+		tabWidget.Pages().Remove(page)
+		page.Dispose()
+		return nil, err
+	}
+
+	tabs = append(tabs, t)
+	return t, nil
+}
+
+// tabByPath returns the tab an archive is already open in
+func tabByPath(path string) *archiveTab {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	for _, t := range tabs {
+		tabPath, err := filepath.Abs(t.sess.Path())
+		if err != nil {
+			tabPath = t.sess.Path()
+		}
+		if strings.EqualFold(tabPath, absPath) {
+			return t
+		}
+	}
+	return nil
+}
+
+// tabBySession returns the tab showing sess, nil once it is closed
+func tabBySession(sess *session.Session) *archiveTab {
+	for _, t := range tabs {
+		if t.sess == sess {
+			return t
+		}
+	}
+	return nil
+}
+
+// setActiveTab points the menu handlers at a tab and restores its title and status
+func setActiveTab(t *archiveTab) {
+	activeTab = t
+	if t == nil {
+		current = nil
+		file = nil
+		fileView = nil
+		setJumpWorldEnabled(false)
+		setJumpLightEnabled(false)
+		setJumpObjectEnabled(false)
+		entrySetActive(false)
+		updateTitle()
+		rebuildRecentMenu()
+		updatePreview()
+		statusBar.SetText("Ready")
+		return
+	}
+
+	current = t.sess
+	file = t.table
+	fileView = t.fileView
+
+	idx := tabWidget.Pages().Index(t.page)
+	if idx >= 0 && tabWidget.CurrentIndex() != idx {
+		err := tabWidget.SetCurrentIndex(idx)
+		if err != nil {
+			slog.Printf("Failed to select tab %s: %s\n", t.sess.Name(), err.Error())
+		}
+	}
+
+	updateJumps()
+	entrySetActive(file.CurrentIndex() >= 0)
+	updateTitle()
+	rebuildRecentMenu()
+	updatePreview()
+	statusBar.SetText(t.status)
+}
+
+// closeTab removes a tab and activates its neighbour
+func closeTab(t *archiveTab) error {
+	idx := -1
+	for i, tab := range tabs {
+		if tab == t {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("tab not found")
+	}
+
+	err := t.sess.Close()
+	if err != nil {
+		return err
+	}
+
+	tabs = append(tabs[:idx], tabs[idx+1:]...)
+	err = tabWidget.Pages().Remove(t.page)
+	if err != nil {
+		return fmt.Errorf("remove tab page: %w", err)
+	}
+	t.page.Dispose()
+	disposeIcons(t.icons, nil)
+	t.icons = nil
+
+	if len(tabs) == 0 {
+		setActiveTab(nil)
+		return nil
+	}
+	if idx >= len(tabs) {
+		idx = len(tabs) - 1
+	}
+	setActiveTab(tabs[idx])
+	return nil
+}
+
+func onTabChange() {
+	idx := tabWidget.CurrentIndex()
+	if idx < 0 || idx >= len(tabs) {
+		return
+	}
+	for _, t := range tabs {
+		if tabWidget.Pages().Index(t.page) != idx {
+			continue
+		}
+		if t == activeTab {
+			return
+		}
+		setActiveTab(t)
+		return
+	}
+}
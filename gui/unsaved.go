@@ -0,0 +1,58 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/wlk/walk"
+)
+
+// isExitConfirmed skips the unsaved changes check once exit was already confirmed
+var isExitConfirmed bool
+
+// confirmDiscard asks to save a tab with unsaved changes, returning false if the user cancelled
+func confirmDiscard(t *archiveTab) bool {
+	if t == nil || !t.sess.IsDirty() {
+		return true
+	}
+	if t != activeTab {
+		setActiveTab(t)
+	}
+
+	msg := fmt.Sprintf("Save changes to %s before closing it?", t.sess.Name())
+	switch popup.MessageBoxYesNoCancel(mw, "Unsaved Changes", msg) {
+	case walk.DlgCmdYes:
+		err := t.sess.Save()
+		if err != nil {
+			popup.Errorf(mw, "save: %s", err)
+			return false
+		}
+		slog.Printf("Saved %s\n", t.sess.Name())
+		return true
+	case walk.DlgCmdNo:
+		return true
+	}
+	return false
+}
+
+// confirmDiscardAll runs confirmDiscard on every open tab
+func confirmDiscardAll() bool {
+	for _, t := range tabs {
+		if !confirmDiscard(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func onClosing(canceled *bool, reason byte) {
+	if isExitConfirmed {
+		return
+	}
+	if !confirmDiscardAll() {
+		*canceled = true
+		return
+	}
+	isExitConfirmed = true
+}
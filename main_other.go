@@ -0,0 +1,20 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/xackery/quail-gui/cli"
+)
+
+// runGUI is unavailable off windows, only headless commands are supported
+func runGUI() {
+	fmt.Fprintln(os.Stderr, "quail-gui: the gui is only available on windows, use a headless command")
+	cli.Usage()
+	os.Exit(2)
+}
+
+// attachConsole is a no-op off windows, stdout is always available
+func attachConsole() {}
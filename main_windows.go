@@ -0,0 +1,171 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	_ "embed"
+
+	"github.com/xackery/quail-gui/cli"
+	"github.com/xackery/quail-gui/config"
+	"github.com/xackery/quail-gui/gui"
+	"github.com/xackery/quail-gui/ico"
+	"github.com/xackery/quail-gui/popup"
+	"github.com/xackery/quail-gui/session"
+	"github.com/xackery/quail-gui/slog"
+	"github.com/xackery/quail/raw"
+)
+
+func runGUI() {
+	start := time.Now()
+
+	cfg, err := config.New(context.Background(), "quail-gui")
+	if err != nil {
+		popup.Errorf(gui.MainWindow(), "config new: %s", err)
+		os.Exit(1)
+	}
+	session.SetBackupCount(cfg.BackupCount)
+
+	err = ico.Init()
+	if err != nil {
+		popup.Errorf(gui.MainWindow(), "ico init: %s", err)
+		os.Exit(1)
+	}
+
+	exeName, err := os.Executable()
+	if err != nil {
+		popup.MessageBox(gui.MainWindow(), "Error", "Failed to get executable name", true)
+		os.Exit(1)
+	}
+	baseName := filepath.Base(exeName)
+	if strings.Contains(baseName, ".") {
+		baseName = baseName[0:strings.Index(baseName, ".")]
+	}
+
+	fileToOpen := ""
+	if len(os.Args) > 1 {
+		fileToOpen = os.Args[1]
+	}
+
+	err = gui.New()
+	if err != nil {
+		slog.Printf("Failed to create main window: %s", err.Error())
+		os.Exit(1)
+	}
+
+	defer slog.Dump()
+
+	if len(fileToOpen) > 1 {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			ext := strings.ToLower(filepath.Ext(fileToOpen))
+			if !isArchive(ext) {
+				err = quickEditFile(fileToOpen)
+				if err != nil {
+					if err.Error() == "cancelled" {
+						slog.Printf("Cancelled edit %s\n", baseName)
+						return
+					}
+
+					popup.Errorf(gui.MainWindow(), "show edit: %s", err)
+					os.Exit(1)
+				}
+				os.Exit(0)
+			}
+
+			err = gui.Open(fileToOpen)
+			if err != nil {
+				popup.Errorf(gui.MainWindow(), "gui open: %s", err)
+				return
+			}
+		}()
+	}
+
+	slog.Printf("Started in %s\n", time.Since(start).String())
+	errCode := gui.Run()
+	if errCode != 0 {
+		fmt.Println("Failed to run:", errCode)
+		os.Exit(1)
+	}
+
+}
+
+// attachConsole points headless output at the console quail-gui was started
+// from. Release builds link with -H=windowsgui and get no console of their own,
+// so without this every command would run silently
+func attachConsole() {
+	_, err := os.Stdout.Stat()
+	if err == nil {
+		return // console build, or output is redirected to a file or pipe
+	}
+
+	const attachParentProcess = ^uint32(0)
+	ok, _, _ := syscall.NewLazyDLL("kernel32.dll").NewProc("AttachConsole").Call(uintptr(attachParentProcess))
+	if ok == 0 {
+		return // started from explorer, there is no console to attach to
+	}
+
+	out, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	os.Stdout = out
+	os.Stderr = out
+	cli.SetOutput(out, out)
+}
+
+func isArchive(ext string) bool {
+	switch ext {
+	case ".pfs", ".eqg", ".s3d", ".pak":
+		return true
+	}
+	return false
+}
+
+// open a non-archive file
+func quickEditFile(path string) error {
+
+	ext := strings.ToLower(filepath.Ext(path))
+
+	slog.Printf("Opening path: %s\n", path)
+
+	r, err := os.Open(path)
+	if err != nil {
+		popup.Errorf(gui.MainWindow(), "os open: %s", err)
+		os.Exit(1)
+	}
+	defer r.Close()
+
+	value, err := raw.Read(ext, r)
+	if err != nil {
+		popup.Errorf(gui.MainWindow(), "raw read: %s", err)
+		os.Exit(1)
+	}
+
+	data, err := gui.DialogEdit(path, value)
+	if err != nil {
+		if err.Error() == "cancelled" {
+			slog.Printf("Cancelled without saving\n")
+			return nil
+		}
+		return err
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		popup.Errorf(gui.MainWindow(), "os write: %s", err)
+		os.Exit(1)
+	}
+
+	slog.Printf("Saved %s\n", path)
+
+	return nil
+
+}
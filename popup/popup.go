@@ -44,6 +44,63 @@ func Open(wnd walk.Form, title string, filter string, initialDirPath string) (st
 	return dialog.FilePath, nil
 }
 
+func OpenMultiple(wnd walk.Form, title string, filter string, initialDirPath string) ([]string, error) {
+	if wnd == nil {
+		return nil, fmt.Errorf("gui not initialized")
+	}
+	dialog := walk.FileDialog{
+		Title:          title,
+		Filter:         filter,
+		InitialDirPath: initialDirPath,
+	}
+	ok, err := dialog.ShowOpenMultiple(wnd)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("cancelled")
+	}
+	return dialog.FilePaths, nil
+}
+
+func BrowseFolder(wnd walk.Form, title string, initialDirPath string) (string, error) {
+	if wnd == nil {
+		return "", fmt.Errorf("gui not initialized")
+	}
+	dialog := walk.FileDialog{
+		Title:          title,
+		InitialDirPath: initialDirPath,
+	}
+	ok, err := dialog.ShowBrowseFolder(wnd)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("cancelled")
+	}
+	return dialog.FilePath, nil
+}
+
+func Save(wnd walk.Form, title string, filter string, initialDirPath string, fileName string) (string, error) {
+	if wnd == nil {
+		return "", fmt.Errorf("gui not initialized")
+	}
+	dialog := walk.FileDialog{
+		Title:          title,
+		Filter:         filter,
+		InitialDirPath: initialDirPath,
+		FilePath:       fileName,
+	}
+	ok, err := dialog.ShowSave(wnd)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("cancelled")
+	}
+	return dialog.FilePath, nil
+}
+
 func MessageBox(wnd walk.Form, title string, message string, isError bool) {
 
 	icon := walk.MsgBoxIconInformation
@@ -64,6 +121,12 @@ func MessageBoxYesNo(wnd walk.Form, title string, message string) bool {
 	return result == walk.DlgCmdYes
 }
 
+// MessageBoxYesNoCancel returns walk.DlgCmdYes, walk.DlgCmdNo or walk.DlgCmdCancel
+func MessageBoxYesNoCancel(wnd walk.Form, title string, message string) int {
+	icon := walk.MsgBoxIconWarning
+	return walk.MsgBox(wnd, title, message, icon|walk.MsgBoxYesNoCancel)
+}
+
 func MessageBoxf(wnd walk.Form, title string, format string, a ...interface{}) {
 	icon := walk.MsgBoxIconInformation
 	walk.MsgBox(wnd, title, fmt.Sprintf(format, a...), icon)
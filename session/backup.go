@@ -0,0 +1,207 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xackery/quail/pfs"
+)
+
+var (
+	backupCount = 3 // how many .bak copies are kept per archive, 0 disables backups
+)
+
+// SetBackupCount sets how many timestamped backups are kept per archive
+func SetBackupCount(count int) {
+	if count < 0 {
+		count = 0
+	}
+	backupCount = count
+}
+
+// Backups returns the backups of the archive, newest first
+func (s *Session) Backups() ([]string, error) {
+	return backups(s.path)
+}
+
+// RestoreBackup replaces the archive on disk with a backup and reloads it,
+// discarding any unsaved changes
+func (s *Session) RestoreBackup(backupPath string) error {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("read backup: %w", err)
+	}
+
+	archive, err := pfs.New(filepath.Base(s.path))
+	if err != nil {
+		return fmt.Errorf("pfs.New: %w", err)
+	}
+	err = archive.Read(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode backup: %w", err)
+	}
+
+	err = writeAtomic(s.path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	oldArchive := s.archive
+	s.archive = archive
+	s.isDirty = false
+	s.isCleanDirty = false
+	s.edited = make(map[string]bool)
+	s.infos = nil
+	s.clearHistory()
+	err = oldArchive.Close()
+	if err != nil {
+		return fmt.Errorf("close old archive: %w", err)
+	}
+	return nil
+}
+
+// writeAtomic writes to a temp file next to path and renames it over path once
+// synced, so a failed write never leaves a truncated archive behind
+func writeAtomic(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	w, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp: %w", err)
+	}
+	tmpPath := w.Name()
+	isRenamed := false
+	defer func() {
+		if !isRenamed {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	err = write(w)
+	if err != nil {
+		w.Close()
+		return fmt.Errorf("write: %w", err)
+	}
+	err = w.Sync()
+	if err != nil {
+		w.Close()
+		return fmt.Errorf("sync: %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("close temp: %w", err)
+	}
+
+	err = backup(path)
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	isRenamed = true
+	return nil
+}
+
+// backup copies path to a timestamped .bak and prunes the oldest copies
+func backup(path string) error {
+	if backupCount == 0 {
+		return nil
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer r.Close()
+
+	w, err := createBackup(path, time.Now())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return fmt.Errorf("copy: %w", err)
+	}
+	err = w.Sync()
+	if err != nil {
+		w.Close()
+		return fmt.Errorf("sync: %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	paths, err := backups(path)
+	if err != nil {
+		return err
+	}
+	for i := backupCount; i < len(paths); i++ {
+		err = os.Remove(paths[i])
+		if err != nil {
+			return fmt.Errorf("prune %s: %w", filepath.Base(paths[i]), err)
+		}
+	}
+	return nil
+}
+
+// createBackup creates a new backup file of path named after now, never
+// overwriting an earlier backup made within the same microsecond
+func createBackup(path string, now time.Time) (*os.File, error) {
+	stamp := now.Format("20060102-150405.000000")
+	for i := 0; i < 100; i++ {
+		backupPath := fmt.Sprintf("%s.%s.bak", path, stamp)
+		if i > 0 {
+			backupPath = fmt.Sprintf("%s.%s_%02d.bak", path, stamp, i)
+		}
+		w, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return w, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("too many backups at %s", stamp)
+}
+
+// backups returns the backups of path, newest first. Only names made by
+// createBackup match, so foo.s3d never picks up the backups of foo.s3d.old.s3d
+func backups(path string) ([]string, error) {
+	dir := filepath.Dir(path)
+	pattern, err := regexp.Compile(`^` + regexp.QuoteMeta(strings.ToLower(filepath.Base(path))) + `\.\d{8}-\d{6}\.\d{6}(_\d{2})?\.bak$`)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !pattern.MatchString(strings.ToLower(entry.Name())) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	// the stamp orders the names, compare them without case like the match
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.ToLower(filepath.Base(paths[i])) > strings.ToLower(filepath.Base(paths[j]))
+	})
+	return paths, nil
+}
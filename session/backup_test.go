@@ -0,0 +1,103 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackupRotation(t *testing.T) {
+	defer SetBackupCount(backupCount)
+
+	tests := []struct {
+		name        string
+		count       int
+		saves       int
+		wantBackups int
+	}{
+		{name: "disabled", count: 0, saves: 3, wantBackups: 0},
+		{name: "under limit", count: 3, saves: 2, wantBackups: 2},
+		{name: "at limit", count: 3, saves: 3, wantBackups: 3},
+		{name: "pruned", count: 2, saves: 5, wantBackups: 2},
+		{name: "negative is disabled", count: -1, saves: 2, wantBackups: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetBackupCount(tt.count)
+			s, err := Open(writeArchive(t, "a.txt"))
+			if err != nil {
+				t.Fatalf("open: %s", err)
+			}
+			defer s.Close()
+
+			for i := 0; i < tt.saves; i++ {
+				err = s.Save()
+				if err != nil {
+					t.Fatalf("save %d: %s", i, err)
+				}
+			}
+			paths, err := s.Backups()
+			if err != nil {
+				t.Fatalf("backups: %s", err)
+			}
+			if len(paths) != tt.wantBackups {
+				t.Errorf("backups = %d, want %d", len(paths), tt.wantBackups)
+			}
+		})
+	}
+}
+
+func TestCreateBackupSameTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.s3d")
+	now := time.Now()
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		w, err := createBackup(path, now)
+		if err != nil {
+			t.Fatalf("create backup %d: %s", i, err)
+		}
+		w.Close()
+		if seen[w.Name()] {
+			t.Fatalf("backup %d reused %s", i, filepath.Base(w.Name()))
+		}
+		seen[w.Name()] = true
+	}
+}
+
+func TestBackupsMatchName(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"test.s3d.20260102-150405.000001.bak",
+		"test.s3d.20260102-150405.000001_01.bak",
+		"TEST.S3D.20260102-150405.000002.BAK",
+		"test.s3d.old.s3d.20260102-150405.000003.bak",
+		"test.s3d.20260102-150405.bak",
+		"test.s3d.notes.bak",
+		"xtest.s3d.20260102-150405.000004.bak",
+	}
+	for _, name := range names {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0644)
+		if err != nil {
+			t.Fatalf("write %s: %s", name, err)
+		}
+	}
+
+	paths, err := backups(filepath.Join(dir, "test.s3d"))
+	if err != nil {
+		t.Fatalf("backups: %s", err)
+	}
+	got := []string{}
+	for _, path := range paths {
+		got = append(got, filepath.Base(path))
+	}
+	want := []string{
+		"TEST.S3D.20260102-150405.000002.BAK",
+		"test.s3d.20260102-150405.000001_01.bak",
+		"test.s3d.20260102-150405.000001.bak",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("backups = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/xackery/quail/pfs"
+)
+
+// Rename is an entry name change
+type Rename struct {
+	From string
+	To   string
+}
+
+// BulkRename computes the renames of a find and replace over names. Only names
+// matching the glob match are renamed, an empty match renames every name. When
+// isRegex is false find is a case insensitive literal.
+func BulkRename(names []string, match string, find string, replace string, isRegex bool) ([]Rename, error) {
+	if find == "" {
+		return nil, fmt.Errorf("find is empty")
+	}
+	pattern := find
+	if !isRegex {
+		pattern = "(?i)" + regexp.QuoteMeta(find)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+	if !isRegex {
+		replace = strings.ReplaceAll(replace, "$", "$$")
+	}
+
+	renames := []Rename{}
+	for _, name := range names {
+		if match != "" {
+			isMatch, err := filepath.Match(strings.ToLower(match), strings.ToLower(name))
+			if err != nil {
+				return nil, fmt.Errorf("match: %w", err)
+			}
+			if !isMatch {
+				continue
+			}
+		}
+		newName := re.ReplaceAllString(name, replace)
+		if newName == name {
+			continue
+		}
+		renames = append(renames, Rename{From: name, To: newName})
+	}
+	return renames, nil
+}
+
+// DeleteAll removes several entries as one change, nothing is removed if any entry is missing
+func (s *Session) DeleteAll(names []string) error {
+	removed := []Rename{}
+	datas := [][]byte{}
+	isRemoved := map[string]bool{}
+	for _, name := range names {
+		fe := s.entry(name)
+		if fe == nil {
+			return fmt.Errorf("entry %s not found", name)
+		}
+		if isRemoved[strings.ToLower(fe.Name())] {
+			continue
+		}
+		isRemoved[strings.ToLower(fe.Name())] = true
+		removed = append(removed, Rename{From: fe.Name()})
+		datas = append(datas, append([]byte{}, fe.Data()...))
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	order := []string{}
+	for _, fe := range s.archive.Files() {
+		order = append(order, fe.Name())
+	}
+	edited := s.editedCopy()
+	for _, rename := range removed {
+		err := s.remove(rename.From)
+		if err != nil {
+			return err
+		}
+	}
+
+	title := "Delete " + removed[0].From
+	if len(removed) > 1 {
+		title = fmt.Sprintf("Delete %d entries", len(removed))
+	}
+	s.record(title, edited,
+		func() error {
+			for i, rename := range removed {
+				err := s.archive.SetFile(rename.From, datas[i])
+				if err != nil {
+					return fmt.Errorf("set file %s: %w", rename.From, err)
+				}
+				s.forget(rename.From)
+			}
+			return s.restoreOrder(order)
+		},
+		func() error {
+			for _, rename := range removed {
+				err := s.remove(rename.From)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	return nil
+}
+
+// RenameAll applies several renames as one change, nothing is renamed if any would collide
+func (s *Session) RenameAll(renames []Rename) error {
+	if len(renames) == 0 {
+		return nil
+	}
+	edited := s.editedCopy()
+	err := s.renameAll(renames)
+	if err != nil {
+		return err
+	}
+
+	reverse := []Rename{}
+	for _, rename := range renames {
+		reverse = append(reverse, Rename{From: rename.To, To: rename.From})
+	}
+	title := fmt.Sprintf("Rename %s to %s", renames[0].From, renames[0].To)
+	if len(renames) > 1 {
+		title = fmt.Sprintf("Rename %d entries", len(renames))
+	}
+	s.record(title, edited,
+		func() error { return s.renameAll(reverse) },
+		func() error { return s.renameAll(renames) })
+	return nil
+}
+
+func (s *Session) renameAll(renames []Rename) error {
+	from := map[string]bool{}
+	for _, rename := range renames {
+		if !s.Has(rename.From) {
+			return fmt.Errorf("entry %s not found", rename.From)
+		}
+		from[strings.ToLower(rename.From)] = true
+	}
+
+	to := map[string]bool{}
+	for _, rename := range renames {
+		if rename.To == "" || filepath.Ext(rename.To) == "" {
+			return fmt.Errorf("rename %s: entry must have an extension", rename.From)
+		}
+		key := strings.ToLower(rename.To)
+		if to[key] {
+			return fmt.Errorf("rename %s: %s is used more than once", rename.From, rename.To)
+		}
+		to[key] = true
+		if s.Has(rename.To) && !from[key] {
+			return fmt.Errorf("rename %s: entry %s already exists", rename.From, rename.To)
+		}
+	}
+
+	// look every entry up before renaming so swapped names do not collide
+	entries := []*pfs.FileEntry{}
+	for _, rename := range renames {
+		entries = append(entries, s.entry(rename.From))
+		delete(s.edited, strings.ToLower(rename.From))
+		s.forget(rename.From)
+	}
+	for i, fe := range entries {
+		s.forget(renames[i].To)
+		fe.SetName(renames[i].To)
+		s.markEdited(renames[i].To)
+	}
+	return nil
+}
+
+// restoreOrder moves entries back to the positions listed in order, entries not
+// in order keep their relative order at the end. SetFile only appends new
+// entries, so every entry is removed and set again in the wanted order
+func (s *Session) restoreOrder(order []string) error {
+	position := map[string]int{}
+	for i, name := range order {
+		position[strings.ToLower(name)] = i
+	}
+	files := append([]*pfs.FileEntry{}, s.archive.Files()...)
+	sort.SliceStable(files, func(i, j int) bool {
+		pi, ok := position[strings.ToLower(files[i].Name())]
+		if !ok {
+			pi = len(order)
+		}
+		pj, ok := position[strings.ToLower(files[j].Name())]
+		if !ok {
+			pj = len(order)
+		}
+		return pi < pj
+	})
+
+	for _, fe := range files {
+		name := fe.Name()
+		data := fe.Data()
+		err := s.archive.Remove(name)
+		if err != nil {
+			return fmt.Errorf("remove %s: %w", name, err)
+		}
+		err = s.archive.SetFile(name, data)
+		if err != nil {
+			return fmt.Errorf("set file %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-gui/texture"
+)
+
+// ExtractProgress is called before each entry is written, returning an error stops the extraction
+type ExtractProgress func(done int, total int, name string) error
+
+// Extract writes entries to dir, every entry if names is empty. When isPNG is
+// true textures are decoded and written as .png instead of their raw data.
+// It returns the paths written.
+func (s *Session) Extract(dir string, names []string, isPNG bool, progress ExtractProgress) ([]string, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+
+	entries := s.archive.Files()
+	if len(names) > 0 {
+		entries = entries[:0:0]
+		for _, name := range names {
+			fe := s.entry(name)
+			if fe == nil {
+				return nil, fmt.Errorf("entry %s not found", name)
+			}
+			entries = append(entries, fe)
+		}
+	}
+
+	// names taken by entries written as-is, so converted textures don't overwrite them
+	isTaken := map[string]bool{}
+	for _, fe := range entries {
+		if !isPNG || !texture.IsTextureExt(filepath.Ext(fe.Name())) {
+			isTaken[strings.ToLower(filepath.Base(fe.Name()))] = true
+		}
+	}
+
+	paths := []string{}
+	for i, fe := range entries {
+		if progress != nil {
+			err = progress(i, len(entries), fe.Name())
+			if err != nil {
+				return paths, err
+			}
+		}
+
+		name := filepath.Base(fe.Name())
+		data := fe.Data()
+		if isPNG && texture.IsTextureExt(filepath.Ext(name)) {
+			buf := &bytes.Buffer{}
+			err = texture.EncodePNG(buf, data)
+			if err != nil {
+				return paths, fmt.Errorf("convert %s: %w", name, err)
+			}
+			pngName := strings.TrimSuffix(name, filepath.Ext(name)) + ".png"
+			if isTaken[strings.ToLower(pngName)] {
+				// foo.dds and foo.bmp would both be foo.png, keep the source extension
+				pngName = name + ".png"
+			}
+			if isTaken[strings.ToLower(pngName)] {
+				return paths, fmt.Errorf("convert %s: %s is already extracted", name, pngName)
+			}
+			name = pngName
+			data = buf.Bytes()
+		}
+		isTaken[strings.ToLower(name)] = true
+
+		path := filepath.Join(dir, name)
+		err = os.WriteFile(path, data, 0644)
+		if err != nil {
+			return paths, fmt.Errorf("write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	if progress != nil {
+		err = progress(len(entries), len(entries), "")
+		if err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
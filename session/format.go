@@ -0,0 +1,54 @@
+package session
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// eqgOnlyExts are formats only read by the client out of .eqg archives
+var eqgOnlyExts = map[string]bool{
+	".mod": true,
+	".mds": true,
+	".ter": true,
+	".zon": true,
+	".lay": true,
+	".ani": true,
+	".pts": true,
+	".prt": true,
+	".lod": true,
+	".lit": true,
+}
+
+// s3dOnlyExts are formats only read by the client out of .s3d archives
+var s3dOnlyExts = map[string]bool{
+	".wld": true,
+}
+
+// IsArchiveExt returns true if ext is a pfs based archive extension
+func IsArchiveExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".pfs", ".eqg", ".s3d", ".pak":
+		return true
+	}
+	return false
+}
+
+// Incompatible returns entries that are not used by an archive with the provided extension
+func (s *Session) Incompatible(archiveExt string) []string {
+	archiveExt = strings.ToLower(archiveExt)
+	names := []string{}
+	for _, fe := range s.archive.Files() {
+		ext := strings.ToLower(filepath.Ext(fe.Name()))
+		switch archiveExt {
+		case ".s3d":
+			if eqgOnlyExts[ext] {
+				names = append(names, fe.Name())
+			}
+		case ".eqg":
+			if s3dOnlyExts[ext] {
+				names = append(names, fe.Name())
+			}
+		}
+	}
+	return names
+}
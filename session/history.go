@@ -0,0 +1,167 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// Change is a reversible edit of the archive
+type Change struct {
+	Title  string
+	Time   time.Time
+	edited map[string]bool // edited markers from before the change
+	undo   func() error
+	redo   func() error
+}
+
+// CanUndo returns true if there is a change to undo
+func (s *Session) CanUndo() bool {
+	return len(s.undos) > 0
+}
+
+// CanRedo returns true if there is an undone change to redo
+func (s *Session) CanRedo() bool {
+	return len(s.redos) > 0
+}
+
+// UndoTitle returns the title of the change Undo would revert
+func (s *Session) UndoTitle() string {
+	if len(s.undos) == 0 {
+		return ""
+	}
+	return s.undos[len(s.undos)-1].Title
+}
+
+// RedoTitle returns the title of the change Redo would apply
+func (s *Session) RedoTitle() string {
+	if len(s.redos) == 0 {
+		return ""
+	}
+	return s.redos[len(s.redos)-1].Title
+}
+
+// History returns the changes made since the last save, oldest first, and
+// the undone changes that can still be redone, most recently undone first
+func (s *Session) History() ([]*Change, []*Change) {
+	undone := []*Change{}
+	for i := len(s.redos) - 1; i >= 0; i-- {
+		undone = append(undone, s.redos[i])
+	}
+	return append([]*Change{}, s.undos...), undone
+}
+
+// Undo reverts the most recent change
+func (s *Session) Undo() error {
+	if len(s.undos) == 0 {
+		return fmt.Errorf("nothing to undo")
+	}
+	change := s.undos[len(s.undos)-1]
+	err := change.undo()
+	if err != nil {
+		return fmt.Errorf("undo %s: %w", change.Title, err)
+	}
+	s.undos = s.undos[:len(s.undos)-1]
+	s.redos = append(s.redos, change)
+
+	s.edited = copyEdited(change.edited)
+	s.isDirty = true
+	if len(s.undos) == 0 {
+		s.isDirty = s.isCleanDirty
+	}
+	return nil
+}
+
+// Redo applies the most recently undone change again
+func (s *Session) Redo() error {
+	if len(s.redos) == 0 {
+		return fmt.Errorf("nothing to redo")
+	}
+	change := s.redos[len(s.redos)-1]
+	err := change.redo()
+	if err != nil {
+		return fmt.Errorf("redo %s: %w", change.Title, err)
+	}
+	s.redos = s.redos[:len(s.redos)-1]
+	s.undos = append(s.undos, change)
+	s.isDirty = true
+	return nil
+}
+
+// Group runs fn and records every change it makes as a single change. If fn
+// fails, the changes it made are undone and the dirty flag, edited markers and
+// redo stack are put back as they were
+func (s *Session) Group(title string, fn func() error) error {
+	start := len(s.undos)
+	isDirty := s.isDirty
+	edited := s.editedCopy()
+	redos := s.redos
+	err := fn()
+	changes := append([]*Change{}, s.undos[start:]...)
+	if err != nil {
+		for i := len(changes) - 1; i >= 0; i-- {
+			undoErr := changes[i].undo()
+			if undoErr != nil {
+				return fmt.Errorf("%w (rollback: %s)", err, undoErr)
+			}
+		}
+		s.undos = s.undos[:start]
+		s.redos = redos
+		s.edited = edited
+		s.isDirty = isDirty
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	s.undos = s.undos[:start]
+	s.record(title, changes[0].edited,
+		func() error {
+			for i := len(changes) - 1; i >= 0; i-- {
+				err := changes[i].undo()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		func() error {
+			for _, change := range changes {
+				err := change.redo()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	return nil
+}
+
+// record pushes a change that was just applied, dropping anything that could be redone
+func (s *Session) record(title string, edited map[string]bool, undo func() error, redo func() error) {
+	s.undos = append(s.undos, &Change{
+		Title:  title,
+		Time:   time.Now(),
+		edited: edited,
+		undo:   undo,
+		redo:   redo,
+	})
+	s.redos = nil
+}
+
+func (s *Session) clearHistory() {
+	s.undos = nil
+	s.redos = nil
+}
+
+func (s *Session) editedCopy() map[string]bool {
+	return copyEdited(s.edited)
+}
+
+func copyEdited(edited map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(edited))
+	for name, value := range edited {
+		out[name] = value
+	}
+	return out
+}
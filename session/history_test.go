@@ -0,0 +1,167 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	tests := []editTest{
+		{
+			name: "undo add",
+			edit: func(s *Session) error {
+				err := s.Add("d.txt", []byte("d"))
+				if err != nil {
+					return err
+				}
+				return s.Undo()
+			},
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name: "undo delete keeps position",
+			edit: func(s *Session) error {
+				err := s.DeleteAll([]string{"a.txt", "b.txt"})
+				if err != nil {
+					return err
+				}
+				return s.Undo()
+			},
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name: "undo rename",
+			edit: func(s *Session) error {
+				err := s.Rename("b.txt", "e.txt")
+				if err != nil {
+					return err
+				}
+				return s.Undo()
+			},
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name: "redo delete",
+			edit: func(s *Session) error {
+				err := s.Delete("a.txt")
+				if err != nil {
+					return err
+				}
+				err = s.Undo()
+				if err != nil {
+					return err
+				}
+				return s.Redo()
+			},
+			wantNames: []string{"b.txt", "c.txt"},
+			wantDirty: true,
+		},
+		{
+			name:      "undo with nothing to undo",
+			edit:      func(s *Session) error { return s.Undo() },
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+	}
+	runEditTests(t, tests)
+}
+
+func TestHistorySave(t *testing.T) {
+	s, err := Open(writeArchive(t, "a.txt"))
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer s.Close()
+	err = s.Add("b.txt", []byte("b"))
+	if err != nil {
+		t.Fatalf("add: %s", err)
+	}
+	err = s.Save()
+	if err != nil {
+		t.Fatalf("save: %s", err)
+	}
+	if s.CanUndo() || s.CanRedo() {
+		t.Errorf("save left undo %v, redo %v", s.CanUndo(), s.CanRedo())
+	}
+}
+
+func TestHistoryGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        func(s *Session) error
+		wantErr   bool
+		wantNames []string
+		wantDirty bool
+		wantUndo  bool
+		wantRedo  bool
+	}{
+		{
+			name: "one step",
+			fn: func(s *Session) error {
+				err := s.Add("d.txt", []byte("d"))
+				if err != nil {
+					return err
+				}
+				return s.Delete("a.txt")
+			},
+			wantNames: []string{"b.txt", "c.txt", "d.txt"},
+			wantDirty: true,
+			wantUndo:  true,
+		},
+		{
+			name: "failed rolls back",
+			fn: func(s *Session) error {
+				err := s.Add("d.txt", []byte("d"))
+				if err != nil {
+					return err
+				}
+				return s.Delete("z.txt")
+			},
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+			wantRedo:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Open(writeArchive(t, "a.txt", "b.txt", "c.txt"))
+			if err != nil {
+				t.Fatalf("open: %s", err)
+			}
+			defer s.Close()
+			// leave a change to redo, a failed group must keep it
+			err = s.Add("e.txt", []byte("e"))
+			if err != nil {
+				t.Fatalf("add: %s", err)
+			}
+			err = s.Undo()
+			if err != nil {
+				t.Fatalf("undo: %s", err)
+			}
+
+			err = s.Group(tt.name, func() error { return tt.fn(s) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("group error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := entryNames(s); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("names = %v, want %v", got, tt.wantNames)
+			}
+			if s.IsDirty() != tt.wantDirty {
+				t.Errorf("dirty = %v, want %v", s.IsDirty(), tt.wantDirty)
+			}
+			if s.CanUndo() != tt.wantUndo || s.CanRedo() != tt.wantRedo {
+				t.Errorf("undo %v, redo %v, want %v, %v", s.CanUndo(), s.CanRedo(), tt.wantUndo, tt.wantRedo)
+			}
+			if !tt.wantUndo {
+				return
+			}
+			err = s.Undo()
+			if err != nil {
+				t.Fatalf("undo group: %s", err)
+			}
+			if got, want := entryNames(s), []string{"a.txt", "b.txt", "c.txt"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("undone names = %v, want %v", got, want)
+			}
+		})
+	}
+}
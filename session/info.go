@@ -0,0 +1,147 @@
+package session
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"hash/crc32"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-gui/texture"
+	"github.com/xackery/quail/pfs"
+)
+
+// Info is metadata about an entry shown alongside its name
+type Info struct {
+	Name           string
+	Ext            string
+	Size           int
+	CRC            uint32
+	CompressedSize int    // estimated size once zlib compressed in the archive, -1 until measured
+	Type           string // format named by the entry header, empty if it isn't recognized
+	IsEdited       bool
+}
+
+// Ratio returns the compressed size as a fraction of the size, -1 until measured
+func (i *Info) Ratio() float64 {
+	if i.CompressedSize < 0 {
+		return -1
+	}
+	if i.Size == 0 {
+		return 0
+	}
+	return float64(i.CompressedSize) / float64(i.Size)
+}
+
+// Info returns metadata about an entry
+func (s *Session) Info(name string) (*Info, error) {
+	fe := s.entry(name)
+	if fe == nil {
+		return nil, fmt.Errorf("entry %s not found", name)
+	}
+	return s.info(fe), nil
+}
+
+// Infos returns metadata about every entry, in archive order
+func (s *Session) Infos() []*Info {
+	infos := []*Info{}
+	for _, fe := range s.archive.Files() {
+		infos = append(infos, s.info(fe))
+	}
+	return infos
+}
+
+// info returns the metadata of fe, reading it only when its data changed since the last call
+func (s *Session) info(fe *pfs.FileEntry) *Info {
+	key := strings.ToLower(fe.Name())
+	cached, ok := s.infos[key]
+	if !ok {
+		data := fe.Data()
+		ext := strings.ToLower(filepath.Ext(fe.Name()))
+		cached = &Info{
+			Name:           fe.Name(),
+			Ext:            ext,
+			Size:           len(data),
+			CRC:            crc32.ChecksumIEEE(data),
+			CompressedSize: -1,
+			Type:           entryType(ext, data),
+		}
+		if s.infos == nil {
+			s.infos = make(map[string]*Info)
+		}
+		s.infos[key] = cached
+	}
+	info := *cached
+	info.IsEdited = s.IsEdited(fe.Name())
+	return &info
+}
+
+// forget drops the cached metadata of an entry after it is changed
+func (s *Session) forget(name string) {
+	delete(s.infos, strings.ToLower(name))
+}
+
+// SetCompressedSize records the measured compressed size of an entry, it is
+// ignored if the entry changed since crc was taken
+func (s *Session) SetCompressedSize(name string, crc uint32, size int) {
+	cached, ok := s.infos[strings.ToLower(name)]
+	if !ok || cached.CRC != crc {
+		return
+	}
+	cached.CompressedSize = size
+}
+
+// CompressedSize estimates the size of data once zlib compressed in an
+// archive. It compresses all of data, so call it off the UI thread
+func CompressedSize(data []byte) int {
+	counter := &countWriter{}
+	w := zlib.NewWriter(counter)
+	_, err := w.Write(data)
+	if err != nil {
+		return 0
+	}
+	err = w.Close()
+	if err != nil {
+		return 0
+	}
+	return counter.n
+}
+
+// entryMagics are the headers of the eqg and wld formats quail reads
+var entryMagics = []struct {
+	magic string
+	name  string
+}{
+	{"EQGM", "mod"},
+	{"EQGS", "mds"},
+	{"EQGT", "ter"},
+	{"EQGA", "ani"},
+	{"EQGZ", "zon"},
+	{"EQTZP", "zon"},
+	{"EQPT", "pts"},
+	{"PTCL", "prt"},
+	{"\x02\x3dPT", "wld"},
+}
+
+// entryType names the format of data from its header, without decoding it
+func entryType(ext string, data []byte) string {
+	if texture.IsTextureExt(ext) {
+		return texture.Format(data)
+	}
+	for _, m := range entryMagics {
+		if bytes.HasPrefix(data, []byte(m.magic)) {
+			return m.name
+		}
+	}
+	return ""
+}
+
+type countWriter struct {
+	n int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
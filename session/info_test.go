@@ -0,0 +1,60 @@
+package session
+
+import (
+	"hash/crc32"
+	"testing"
+)
+
+func TestEntryType(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+		data string
+		want string
+	}{
+		{name: "mod", ext: ".mod", data: "EQGM\x03\x00", want: "mod"},
+		{name: "mds", ext: ".mds", data: "EQGS\x01\x00", want: "mds"},
+		{name: "wld", ext: ".wld", data: "\x02\x3dPT\x00\x55", want: "wld"},
+		{name: "dds", ext: ".dds", data: "DDS \x7c\x00", want: "dds"},
+		{name: "bmp named dds", ext: ".dds", data: "BM\x00\x00", want: "bmp"},
+		{name: "magic in a texture", ext: ".dds", data: "EQGM", want: ""},
+		{name: "unknown header", ext: ".mod", data: "junk", want: ""},
+		{name: "empty", ext: ".wld", data: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := entryType(tt.ext, []byte(tt.data))
+			if got != tt.want {
+				t.Errorf("entryType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCompressedSize(t *testing.T) {
+	s, err := Open(writeArchive(t, "a.txt"))
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer s.Close()
+
+	info, err := s.Info("a.txt")
+	if err != nil {
+		t.Fatalf("info: %s", err)
+	}
+	if info.CompressedSize != -1 || info.Ratio() != -1 {
+		t.Fatalf("unmeasured size %d, ratio %f, want -1", info.CompressedSize, info.Ratio())
+	}
+
+	s.SetCompressedSize("A.TXT", crc32.ChecksumIEEE([]byte("stale")), 1)
+	info, _ = s.Info("a.txt")
+	if info.CompressedSize != -1 {
+		t.Errorf("size with a stale crc = %d, want -1", info.CompressedSize)
+	}
+
+	s.SetCompressedSize("A.TXT", info.CRC, CompressedSize([]byte("a.txt")))
+	info, _ = s.Info("a.txt")
+	if info.CompressedSize <= 0 || info.Ratio() <= 0 {
+		t.Errorf("measured size %d, ratio %f, want both above 0", info.CompressedSize, info.Ratio())
+	}
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xackery/quail-gui/texture"
+	"github.com/xackery/quail/raw"
+)
+
+// previewTextLimit caps how much of a text entry is shown in a preview
+const previewTextLimit = 64 * 1024
+
+// Preview is a quick look at an entry without opening an editor
+type Preview struct {
+	Image image.Image // decoded texture, nil for other entries
+	Text  string      // contents of text entries, or a summary of the entry
+}
+
+// Preview decodes an entry for display
+func (s *Session) Preview(name string) (*Preview, error) {
+	data, err := s.File(name)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePreview(name, data)
+}
+
+// DecodePreview decodes the data of entry name for display. It doesn't touch a
+// session, so it is safe to call off the UI thread
+func DecodePreview(name string, data []byte) (*Preview, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+
+	if texture.IsTextureExt(ext) {
+		img, err := texture.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", name, err)
+		}
+		bounds := img.Bounds()
+		return &Preview{
+			Image: img,
+			Text:  fmt.Sprintf("%s %dx%d", texture.Format(data), bounds.Dx(), bounds.Dy()),
+		}, nil
+	}
+
+	value, err := raw.Read(ext, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	if value == nil {
+		return &Preview{Text: fmt.Sprintf("%d bytes, no preview available", len(data))}, nil
+	}
+
+	switch val := value.(type) {
+	case *raw.Txt:
+		return &Preview{Text: previewText(val.Data)}, nil
+	case *raw.WldAscii:
+		return &Preview{Text: previewText(val.Data)}, nil
+	case *raw.Mod:
+		return &Preview{Text: summary(
+			"Version", val.Version,
+			"Materials", len(val.Materials),
+			"Bones", len(val.Bones),
+			"Vertices", len(val.Vertices),
+			"Triangles", len(val.Triangles),
+		)}, nil
+	case *raw.Mds:
+		return &Preview{Text: summary(
+			"Version", val.Version,
+			"Materials", len(val.Materials),
+			"Bones", len(val.Bones),
+			"Vertices", len(val.Vertices),
+			"Triangles", len(val.Triangles),
+		)}, nil
+	case *raw.Zon:
+		return &Preview{Text: summary(
+			"Version", val.Version,
+			"Models", len(val.Models),
+			"Objects", len(val.Objects),
+			"Regions", len(val.Regions),
+		)}, nil
+	case *raw.Wld:
+		return &Preview{Text: wldSummary(val)}, nil
+	}
+	return &Preview{Text: fmt.Sprintf("%s, %d bytes", value.Identity(), len(data))}, nil
+}
+
+// summary formats label, value pairs one per line
+func summary(pairs ...interface{}) string {
+	lines := []string{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		lines = append(lines, fmt.Sprintf("%s: %v", pairs[i], pairs[i+1]))
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// wldSummary counts the fragments of a wld by type
+func wldSummary(wld *raw.Wld) string {
+	counts := map[string]int{}
+	for _, frag := range wld.Fragments {
+		if frag == nil {
+			continue
+		}
+		name := raw.FragName(frag.FragCode())
+		if name == "" {
+			name = fmt.Sprintf("0x%02x", frag.FragCode())
+		}
+		counts[name]++
+	}
+	names := []string{}
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	text := summary("Version", wld.Version, "Fragments", len(wld.Fragments))
+	for _, name := range names {
+		text += fmt.Sprintf("\r\n  %s: %d", name, counts[name])
+	}
+	return text
+}
+
+// previewText truncates long text and uses windows line endings for edit controls
+func previewText(text string) string {
+	if len(text) > previewTextLimit {
+		end := previewTextLimit
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		text = text[:end] + "\n... (truncated)"
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
@@ -0,0 +1,327 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail/pfs"
+)
+
+// Session is an archive opened for editing, independent of any front end
+type Session struct {
+	path    string
+	archive *pfs.Pfs
+	isDirty bool
+	edited  map[string]bool // lowercase names of entries modified since last save
+
+	undos        []*Change
+	redos        []*Change
+	isCleanDirty bool // isDirty once every change is undone
+
+	infos map[string]*Info // cached entry metadata by lowercase name, see forget
+}
+
+// Open loads an archive from disk
+func Open(path string) (*Session, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	archive, err := pfs.New(filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("pfs.New: %w", err)
+	}
+
+	err = archive.Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	s := &Session{
+		path:    path,
+		archive: archive,
+		edited:  make(map[string]bool),
+	}
+	return s, nil
+}
+
+// Path returns where the archive is saved to
+func (s *Session) Path() string {
+	return s.path
+}
+
+// Name returns the base name of the archive
+func (s *Session) Name() string {
+	return filepath.Base(s.path)
+}
+
+// IsDirty returns true if the archive has unsaved changes
+func (s *Session) IsDirty() bool {
+	return s.isDirty
+}
+
+// IsEdited returns true if an entry was modified since the last save
+func (s *Session) IsEdited(name string) bool {
+	return s.edited[strings.ToLower(name)]
+}
+
+// Files returns every entry in the archive
+func (s *Session) Files() []*pfs.FileEntry {
+	return s.archive.Files()
+}
+
+// Has returns true if an entry exists, names are case insensitive
+func (s *Session) Has(name string) bool {
+	return s.entry(name) != nil
+}
+
+// UniqueName returns name, or name with a numbered suffix if an entry already uses it
+func (s *Session) UniqueName(name string) string {
+	if !s.Has(name) {
+		return name
+	}
+	ext := filepath.Ext(name)
+	baseName := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		newName := fmt.Sprintf("%s_%d%s", baseName, i, ext)
+		if !s.Has(newName) {
+			return newName
+		}
+	}
+}
+
+// File returns the data of an entry
+func (s *Session) File(name string) ([]byte, error) {
+	fe := s.entry(name)
+	if fe == nil {
+		return nil, fmt.Errorf("entry %s not found", name)
+	}
+	return fe.Data(), nil
+}
+
+// Add creates a new entry
+func (s *Session) Add(name string, data []byte) error {
+	edited := s.editedCopy()
+	err := s.add(name, data)
+	if err != nil {
+		return err
+	}
+	s.record("Add "+name, edited,
+		func() error { return s.remove(name) },
+		func() error { return s.add(name, data) })
+	return nil
+}
+
+// Replace overwrites the data of an existing entry
+func (s *Session) Replace(name string, data []byte) error {
+	fe := s.entry(name)
+	if fe == nil {
+		return fmt.Errorf("entry %s not found", name)
+	}
+	name = fe.Name()
+	oldData := append([]byte{}, fe.Data()...)
+	edited := s.editedCopy()
+	err := s.replace(name, data)
+	if err != nil {
+		return err
+	}
+	s.record("Edit "+name, edited,
+		func() error { return s.replace(name, oldData) },
+		func() error { return s.replace(name, data) })
+	return nil
+}
+
+// Delete removes an entry
+func (s *Session) Delete(name string) error {
+	return s.DeleteAll([]string{name})
+}
+
+// Rename changes the name of an entry
+func (s *Session) Rename(oldName string, newName string) error {
+	fe := s.entry(oldName)
+	if fe == nil {
+		return fmt.Errorf("entry %s not found", oldName)
+	}
+	oldName = fe.Name()
+	edited := s.editedCopy()
+	err := s.rename(oldName, newName)
+	if err != nil {
+		return err
+	}
+	s.record(fmt.Sprintf("Rename %s to %s", oldName, newName), edited,
+		func() error { return s.rename(newName, oldName) },
+		func() error { return s.rename(oldName, newName) })
+	return nil
+}
+
+func (s *Session) add(name string, data []byte) error {
+	if name == "" {
+		return fmt.Errorf("name is empty")
+	}
+	if filepath.Ext(name) == "" {
+		return fmt.Errorf("entry must have an extension")
+	}
+	if s.Has(name) {
+		return fmt.Errorf("entry %s already exists", name)
+	}
+	err := s.archive.SetFile(name, data)
+	if err != nil {
+		return fmt.Errorf("set file %s: %w", name, err)
+	}
+	s.forget(name)
+	s.markEdited(name)
+	return nil
+}
+
+func (s *Session) replace(name string, data []byte) error {
+	fe := s.entry(name)
+	if fe == nil {
+		return fmt.Errorf("entry %s not found", name)
+	}
+	err := s.archive.SetFile(fe.Name(), data)
+	if err != nil {
+		return fmt.Errorf("set file %s: %w", fe.Name(), err)
+	}
+	s.forget(fe.Name())
+	s.markEdited(fe.Name())
+	return nil
+}
+
+func (s *Session) remove(name string) error {
+	fe := s.entry(name)
+	if fe == nil {
+		return fmt.Errorf("entry %s not found", name)
+	}
+	err := s.archive.Remove(fe.Name())
+	if err != nil {
+		return fmt.Errorf("remove %s: %w", fe.Name(), err)
+	}
+	s.forget(fe.Name())
+	delete(s.edited, strings.ToLower(fe.Name()))
+	s.isDirty = true
+	return nil
+}
+
+func (s *Session) rename(oldName string, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("name is empty")
+	}
+	fe := s.entry(oldName)
+	if fe == nil {
+		return fmt.Errorf("entry %s not found", oldName)
+	}
+	if !strings.EqualFold(oldName, newName) && s.Has(newName) {
+		return fmt.Errorf("entry %s already exists", newName)
+	}
+	delete(s.edited, strings.ToLower(fe.Name()))
+	s.forget(fe.Name())
+	s.forget(newName)
+	fe.SetName(newName)
+	s.markEdited(newName)
+	return nil
+}
+
+// Save writes the archive back to its path
+func (s *Session) Save() error {
+	return s.write(s.path)
+}
+
+// SaveAs writes the archive to a new path, dropping any entries listed in exclude,
+// and switches the session to the new path on success
+func (s *Session) SaveAs(path string, exclude []string) error {
+	if path == "" {
+		return fmt.Errorf("path is empty")
+	}
+	if !IsArchiveExt(filepath.Ext(path)) {
+		return fmt.Errorf("unsupported archive extension %s", filepath.Ext(path))
+	}
+
+	// write a filtered copy so a failed write leaves the session untouched
+	archive := s.archive
+	if len(exclude) > 0 {
+		isExcluded := map[string]bool{}
+		for _, name := range exclude {
+			if !s.Has(name) {
+				return fmt.Errorf("entry %s not found", name)
+			}
+			isExcluded[strings.ToLower(name)] = true
+		}
+		var err error
+		archive, err = pfs.New(filepath.Base(path))
+		if err != nil {
+			return fmt.Errorf("pfs.New: %w", err)
+		}
+		defer archive.Close()
+		for _, fe := range s.archive.Files() {
+			if isExcluded[strings.ToLower(fe.Name())] {
+				continue
+			}
+			err = archive.SetFile(fe.Name(), fe.Data())
+			if err != nil {
+				return fmt.Errorf("set file %s: %w", fe.Name(), err)
+			}
+		}
+	}
+
+	err := writeAtomic(path, archive.Write)
+	if err != nil {
+		return err
+	}
+	for _, name := range exclude {
+		err = s.remove(name)
+		if err != nil {
+			return err
+		}
+	}
+	s.saved()
+	s.path = path
+	return nil
+}
+
+func (s *Session) write(path string) error {
+	err := writeAtomic(path, s.archive.Write)
+	if err != nil {
+		return err
+	}
+	s.saved()
+	return nil
+}
+
+// saved resets the dirty state and history after a successful write
+func (s *Session) saved() {
+	s.isDirty = false
+	s.isCleanDirty = false
+	s.edited = make(map[string]bool)
+	s.clearHistory()
+}
+
+// Close releases the archive
+func (s *Session) Close() error {
+	err := s.archive.Close()
+	if err != nil {
+		return fmt.Errorf("archive close: %w", err)
+	}
+	return nil
+}
+
+func (s *Session) entry(name string) *pfs.FileEntry {
+	for _, fe := range s.archive.Files() {
+		if strings.EqualFold(fe.Name(), name) {
+			return fe
+		}
+	}
+	return nil
+}
+
+func (s *Session) markEdited(name string) {
+	s.edited[strings.ToLower(name)] = true
+	s.isDirty = true
+}
@@ -0,0 +1,249 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/xackery/quail/pfs"
+)
+
+// writeArchive writes an archive of names to a temp dir, each entry holding its own name
+func writeArchive(t *testing.T, names ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.s3d")
+	archive, err := pfs.New(filepath.Base(path))
+	if err != nil {
+		t.Fatalf("pfs.New: %s", err)
+	}
+	for _, name := range names {
+		err = archive.SetFile(name, []byte(name))
+		if err != nil {
+			t.Fatalf("set file %s: %s", name, err)
+		}
+	}
+	w, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	defer w.Close()
+	err = archive.Write(w)
+	if err != nil {
+		t.Fatalf("write: %s", err)
+	}
+	return path
+}
+
+func entryNames(s *Session) []string {
+	names := []string{}
+	for _, fe := range s.Files() {
+		names = append(names, fe.Name())
+	}
+	return names
+}
+
+// sortedNames returns the entry names of s sorted, for archives read back from
+// disk where pfs is free to store entries in its own order
+func sortedNames(s *Session) []string {
+	names := entryNames(s)
+	sort.Strings(names)
+	return names
+}
+
+func TestSessionEdit(t *testing.T) {
+	tests := []editTest{
+		{
+			name:      "open",
+			edit:      func(s *Session) error { return nil },
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:      "add",
+			edit:      func(s *Session) error { return s.Add("d.txt", []byte("d")) },
+			wantNames: []string{"a.txt", "b.txt", "c.txt", "d.txt"},
+			wantDirty: true,
+		},
+		{
+			name:      "add existing",
+			edit:      func(s *Session) error { return s.Add("A.TXT", []byte("a")) },
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:      "add without extension",
+			edit:      func(s *Session) error { return s.Add("d", []byte("d")) },
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:      "rename",
+			edit:      func(s *Session) error { return s.Rename("b.txt", "e.txt") },
+			wantNames: []string{"a.txt", "e.txt", "c.txt"},
+			wantDirty: true,
+		},
+		{
+			name:      "rename onto existing",
+			edit:      func(s *Session) error { return s.Rename("b.txt", "c.txt") },
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:      "delete",
+			edit:      func(s *Session) error { return s.Delete("b.txt") },
+			wantNames: []string{"a.txt", "c.txt"},
+			wantDirty: true,
+		},
+		{
+			name:      "delete missing",
+			edit:      func(s *Session) error { return s.Delete("z.txt") },
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+	}
+	runEditTests(t, tests)
+}
+
+// editTest is an edit on a session opened from a.txt, b.txt and c.txt
+type editTest struct {
+	name      string
+	edit      func(s *Session) error
+	wantErr   bool
+	wantNames []string
+	wantDirty bool
+}
+
+func runEditTests(t *testing.T, tests []editTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Open(writeArchive(t, "a.txt", "b.txt", "c.txt"))
+			if err != nil {
+				t.Fatalf("open: %s", err)
+			}
+			defer s.Close()
+
+			err = tt.edit(s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("edit error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := entryNames(s); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("names = %v, want %v", got, tt.wantNames)
+			}
+			if s.IsDirty() != tt.wantDirty {
+				t.Errorf("dirty = %v, want %v", s.IsDirty(), tt.wantDirty)
+			}
+		})
+	}
+}
+
+func TestSessionSave(t *testing.T) {
+	path := writeArchive(t, "a.txt", "b.txt")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	err = s.Add("c.txt", []byte("c"))
+	if err != nil {
+		t.Fatalf("add: %s", err)
+	}
+	err = s.Rename("a.txt", "d.txt")
+	if err != nil {
+		t.Fatalf("rename: %s", err)
+	}
+	err = s.Save()
+	if err != nil {
+		t.Fatalf("save: %s", err)
+	}
+	if s.IsDirty() || s.IsEdited("c.txt") {
+		t.Errorf("save left dirty %v, edited %v", s.IsDirty(), s.IsEdited("c.txt"))
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %s", err)
+	}
+	defer s.Close()
+	want := []string{"b.txt", "c.txt", "d.txt"}
+	if got := sortedNames(s); !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+}
+
+func TestSessionSaveAs(t *testing.T) {
+	tests := []struct {
+		name      string
+		ext       string
+		exclude   []string
+		wantErr   bool
+		wantNames []string // of the session after saving
+		wantSaved []string // of the written archive, nil if none is written
+	}{
+		{
+			name:      "all entries",
+			ext:       ".s3d",
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+			wantSaved: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:      "exclude",
+			ext:       ".s3d",
+			exclude:   []string{"B.TXT"},
+			wantNames: []string{"a.txt", "c.txt"},
+			wantSaved: []string{"a.txt", "c.txt"},
+		},
+		{
+			name:      "exclude missing",
+			ext:       ".s3d",
+			exclude:   []string{"z.txt"},
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:      "unsupported extension",
+			ext:       ".zip",
+			exclude:   []string{"b.txt"},
+			wantErr:   true,
+			wantNames: []string{"a.txt", "b.txt", "c.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Open(writeArchive(t, "a.txt", "b.txt", "c.txt"))
+			if err != nil {
+				t.Fatalf("open: %s", err)
+			}
+			defer s.Close()
+
+			path := filepath.Join(t.TempDir(), "out"+tt.ext)
+			err = s.SaveAs(path, tt.exclude)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("save as error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := entryNames(s); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("session names = %v, want %v", got, tt.wantNames)
+			}
+			if tt.wantSaved == nil {
+				_, err = os.Stat(path)
+				if !os.IsNotExist(err) {
+					t.Errorf("%s was written", filepath.Base(path))
+				}
+				return
+			}
+			if s.Path() != path {
+				t.Errorf("path = %s, want %s", s.Path(), path)
+			}
+
+			saved, err := Open(path)
+			if err != nil {
+				t.Fatalf("open saved: %s", err)
+			}
+			defer saved.Close()
+			if got := sortedNames(saved); !reflect.DeepEqual(got, tt.wantSaved) {
+				t.Errorf("saved names = %v, want %v", got, tt.wantSaved)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail/pfs"
+	"github.com/xackery/quail/raw"
+)
+
+// Template seeds a new archive with empty entries
+type Template struct {
+	Name    string
+	Ext     string   // archive extension the template is made for
+	Entries []string // entry names, %s is replaced with the archive base name
+}
+
+// Templates are the archive layouts offered by File > New
+var Templates = []*Template{
+	{Name: "Empty PFS archive", Ext: ".pfs"},
+	{Name: "Empty S3D archive", Ext: ".s3d"},
+	{Name: "Empty EQG archive", Ext: ".eqg"},
+	{Name: "S3D zone (wld, objects, lights)", Ext: ".s3d", Entries: []string{"%s.wld", "objects.wld", "lights.wld"}},
+	{Name: "EQG model (mod)", Ext: ".eqg", Entries: []string{"%s.mod"}},
+	{Name: "EQG zone (zon)", Ext: ".eqg", Entries: []string{"%s.zon"}},
+}
+
+// New creates an empty archive that is written to path on the first save,
+// seeded with the entries of tmpl if it is not nil
+func New(path string, tmpl *Template) (*Session, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if !IsArchiveExt(ext) {
+		return nil, fmt.Errorf("unsupported archive extension %s", ext)
+	}
+
+	archive, err := pfs.New(filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("pfs.New: %w", err)
+	}
+
+	s := &Session{
+		path:    path,
+		archive: archive,
+		isDirty: true,
+		edited:  make(map[string]bool),
+
+		isCleanDirty: true,
+	}
+	if tmpl == nil {
+		return s, nil
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, entry := range tmpl.Entries {
+		name := strings.ToLower(strings.ReplaceAll(entry, "%s", baseName))
+		data, err := NewEntryData(name)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		err = s.add(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// NewEntryData returns the contents of an empty entry for the extension of name
+func NewEntryData(name string) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return nil, fmt.Errorf("entry must have an extension")
+	}
+
+	var rawWriter raw.ReadWriter
+	switch ext {
+	case ".mod":
+		rawWriter = &raw.Mod{MetaFileName: name}
+	case ".txt":
+		return []byte(name), nil
+	case ".wld":
+		rawWriter = &raw.Wld{MetaFileName: name}
+	case ".zon":
+		rawWriter = &raw.Zon{MetaFileName: name}
+	default:
+		return nil, fmt.Errorf("unsupported extension %s", ext)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	err := rawWriter.Write(buf)
+	if err != nil {
+		return nil, fmt.Errorf("write %s: %w", ext, err)
+	}
+	return buf.Bytes(), nil
+}
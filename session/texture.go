@@ -0,0 +1,206 @@
+package session
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-gui/texture"
+)
+
+// ConvertTexture re-encodes a texture entry as target and renames it to the
+// extension of target. With isUpdateRefs, texture names in materials of other
+// entries are renamed too. It returns the new name and the entries whose
+// references were updated
+func (s *Session) ConvertTexture(name string, target string, isUpdateRefs bool) (string, []string, error) {
+	fe := s.entry(name)
+	if fe == nil {
+		return "", nil, fmt.Errorf("entry %s not found", name)
+	}
+	name = fe.Name()
+	if !texture.IsTextureExt(filepath.Ext(name)) {
+		return "", nil, fmt.Errorf("%s is not a texture", name)
+	}
+
+	data, err := texture.Convert(fe.Data(), target)
+	if err != nil {
+		return "", nil, fmt.Errorf("convert %s: %w", name, err)
+	}
+	newName := strings.TrimSuffix(name, filepath.Ext(name)) + texture.TargetExt(target)
+	if isUpperCase(name) {
+		newName = strings.ToUpper(newName)
+	}
+	isRenamed := !strings.EqualFold(name, newName)
+	if isRenamed && s.Has(newName) {
+		return "", nil, fmt.Errorf("entry %s already exists", newName)
+	}
+
+	updated := []string{}
+	err = s.Group(fmt.Sprintf("Convert %s to %s", name, target), func() error {
+		err := s.Replace(name, data)
+		if err != nil {
+			return err
+		}
+		if !isRenamed {
+			return nil
+		}
+		err = s.Rename(name, newName)
+		if err != nil {
+			return err
+		}
+		if !isUpdateRefs {
+			return nil
+		}
+		updated, err = s.renameReferences(name, newName)
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return newName, updated, nil
+}
+
+// renameReferences replaces oldName with newName where mod, mds, ter and wld
+// entries store texture names, returning the entries that changed. Only the
+// name bytes are patched, the rest of an entry is kept exactly as it was, so
+// both names must be the same length. Converting keeps the base name and
+// swaps one three letter extension for another, so they always are
+func (s *Session) renameReferences(oldName string, newName string) ([]string, error) {
+	if len(oldName) != len(newName) {
+		return nil, fmt.Errorf("rename %s to %s: names differ in length", oldName, newName)
+	}
+	updated := []string{}
+	for _, fe := range s.Files() {
+		data := append([]byte{}, fe.Data()...)
+		count := 0
+		switch entryType(filepath.Ext(fe.Name()), data) {
+		case "mod", "mds", "ter":
+			count = renameInPool(data, oldName, newName)
+		case "wld":
+			count = renameInWld(data, oldName, newName)
+		}
+		if count == 0 {
+			continue
+		}
+		err := s.Replace(fe.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, fe.Name())
+	}
+	return updated, nil
+}
+
+// poolOffsets are where the string pool of an eqg model starts, right after
+// its header. Every header stores the pool size at offset 8
+var poolOffsets = map[string]int{
+	"EQGM": 28,
+	"EQGS": 24,
+	"EQGT": 24,
+}
+
+// renameInPool renames every null terminated oldName in the string pool of an
+// eqg model, returning how many were renamed
+func renameInPool(data []byte, oldName string, newName string) int {
+	if len(data) < 12 {
+		return 0
+	}
+	start, ok := poolOffsets[string(data[:4])]
+	if !ok {
+		return 0
+	}
+	end := start + int(binary.LittleEndian.Uint32(data[8:12]))
+	if end > len(data) || end < start {
+		return 0
+	}
+
+	count := 0
+	for i := start; i < end; {
+		n := bytes.IndexByte(data[i:end], 0)
+		if n < 0 {
+			n = end - i
+		}
+		if strings.EqualFold(string(data[i:i+n]), oldName) {
+			copy(data[i:], matchCase(string(data[i:i+n]), newName))
+			count++
+		}
+		i += n + 1
+	}
+	return count
+}
+
+// wldKey encodes the strings of a wld
+var wldKey = []byte{0x95, 0x3A, 0xC5, 0x2A, 0x95, 0x7A, 0x95, 0x6A}
+
+// renameInWld renames oldName in the file names of the bitmap fragments (0x03)
+// of a wld, returning how many were renamed
+func renameInWld(data []byte, oldName string, newName string) int {
+	const headerSize = 28
+	if len(data) < headerSize {
+		return 0
+	}
+	fragmentCount := int(binary.LittleEndian.Uint32(data[8:12]))
+	offset := headerSize + int(binary.LittleEndian.Uint32(data[20:24]))
+
+	count := 0
+	for i := 0; i < fragmentCount; i++ {
+		if offset+8 > len(data) {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(data[offset:]))
+		code := binary.LittleEndian.Uint32(data[offset+4:])
+		start := offset + 8
+		end := start + size
+		if end > len(data) || end < start {
+			break
+		}
+		offset = end
+		if code != 0x03 {
+			continue
+		}
+
+		// name reference and file count, then a length prefixed name per file
+		for pos := start + 8; pos+2 <= end; {
+			n := int(binary.LittleEndian.Uint16(data[pos:]))
+			pos += 2
+			if n == 0 || pos+n > end {
+				break
+			}
+			name := decodeWldString(data[pos : pos+n])
+			name = strings.TrimRight(name, "\x00")
+			if strings.EqualFold(name, oldName) {
+				encoded := []byte(matchCase(name, newName))
+				for j := range encoded {
+					encoded[j] ^= wldKey[j%len(wldKey)]
+				}
+				copy(data[pos:], encoded)
+				count++
+			}
+			pos += n
+		}
+	}
+	return count
+}
+
+// decodeWldString reverses the xor encoding of a wld string
+func decodeWldString(data []byte) string {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ wldKey[i%len(wldKey)]
+	}
+	return string(out)
+}
+
+// matchCase returns name upper cased if original was upper case
+func matchCase(original string, name string) string {
+	if isUpperCase(original) {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+func isUpperCase(name string) bool {
+	return name == strings.ToUpper(name) && name != strings.ToLower(name)
+}
@@ -0,0 +1,121 @@
+package session
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// testModel returns an eqg model of magic whose string pool holds names
+func testModel(magic string, headerSize int, names ...string) []byte {
+	pool := []byte{}
+	for _, name := range names {
+		pool = append(pool, name...)
+		pool = append(pool, 0)
+	}
+	data := make([]byte, headerSize)
+	copy(data, magic)
+	binary.LittleEndian.PutUint32(data[8:], uint32(len(pool)))
+	data = append(data, pool...)
+	return append(data, "tail.dds\x00"...)
+}
+
+func withPoolSize(data []byte, size uint32) []byte {
+	binary.LittleEndian.PutUint32(data[8:], size)
+	return data
+}
+
+// testWld returns a wld holding one bitmap fragment naming files, after a
+// fragment of another type that also holds a matching name
+func testWld(files ...string) []byte {
+	data := make([]byte, 28)
+	binary.LittleEndian.PutUint32(data, 0x54503D02)
+	binary.LittleEndian.PutUint32(data[8:], 2)
+	binary.LittleEndian.PutUint32(data[20:], 4)
+	data = append(data, 0, 0, 0, 0)
+
+	other := encodeWldString("a.bmp\x00")
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(other)))
+	data = binary.LittleEndian.AppendUint32(data, 0x05)
+	data = append(data, other...)
+
+	frag := make([]byte, 8)
+	binary.LittleEndian.PutUint32(frag[4:], uint32(len(files)))
+	for _, file := range files {
+		name := encodeWldString(file + "\x00")
+		frag = binary.LittleEndian.AppendUint16(frag, uint16(len(name)))
+		frag = append(frag, name...)
+	}
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(frag)))
+	data = binary.LittleEndian.AppendUint32(data, 0x03)
+	return append(data, frag...)
+}
+
+func encodeWldString(s string) []byte {
+	out := []byte(s)
+	for i := range out {
+		out[i] ^= wldKey[i%len(wldKey)]
+	}
+	return out
+}
+
+func TestRenameReferences(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		rename    func(data []byte) int
+		want      []byte
+		wantCount int
+	}{
+		{
+			name:      "mod",
+			data:      testModel("EQGM", 28, "a.bmp", "xa.bmp", "A.BMP"),
+			rename:    func(data []byte) int { return renameInPool(data, "a.bmp", "a.dds") },
+			want:      testModel("EQGM", 28, "a.dds", "xa.bmp", "A.DDS"),
+			wantCount: 2,
+		},
+		{
+			name:      "mds",
+			data:      testModel("EQGS", 24, "b.png", "a.bmp"),
+			rename:    func(data []byte) int { return renameInPool(data, "a.bmp", "a.png") },
+			want:      testModel("EQGS", 24, "b.png", "a.png"),
+			wantCount: 1,
+		},
+		{
+			name:   "outside the pool",
+			data:   testModel("EQGT", 24, "b.png"),
+			rename: func(data []byte) int { return renameInPool(data, "tail.dds", "tail.png") },
+			want:   testModel("EQGT", 24, "b.png"),
+		},
+		{
+			name:   "pool size past the end",
+			data:   withPoolSize(testModel("EQGM", 28, "a.bmp"), 1000),
+			rename: func(data []byte) int { return renameInPool(data, "a.bmp", "a.dds") },
+			want:   withPoolSize(testModel("EQGM", 28, "a.bmp"), 1000),
+		},
+		{
+			name:      "wld",
+			data:      testWld("b.bmp", "A.BMP"),
+			rename:    func(data []byte) int { return renameInWld(data, "a.bmp", "a.dds") },
+			want:      testWld("b.bmp", "A.DDS"),
+			wantCount: 1,
+		},
+		{
+			name:   "wld without a match",
+			data:   testWld("b.bmp"),
+			rename: func(data []byte) int { return renameInWld(data, "a.bmp", "a.dds") },
+			want:   testWld("b.bmp"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := tt.rename(tt.data)
+			if count != tt.wantCount {
+				t.Errorf("renamed %d, want %d", count, tt.wantCount)
+			}
+			if !bytes.Equal(tt.data, tt.want) {
+				t.Errorf("data = %q, want %q", tt.data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package texture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sort"
+	"strings"
+
+	"github.com/sergeymakinen/go-bmp"
+)
+
+// Conversion targets for Convert
+const (
+	TargetPNG  = "png"
+	TargetBMP  = "bmp"  // 8-bit paletted bmp
+	TargetDXT1 = "dxt1" // dds, 1-bit alpha
+	TargetDXT5 = "dxt5" // dds, interpolated alpha
+)
+
+// Targets lists every conversion target, in menu order
+var Targets = []string{TargetDXT1, TargetDXT5, TargetBMP, TargetPNG}
+
+// TargetExt returns the entry extension a conversion target is stored as
+func TargetExt(target string) string {
+	switch target {
+	case TargetDXT1, TargetDXT5:
+		return ".dds"
+	case TargetBMP:
+		return ".bmp"
+	}
+	return ".png"
+}
+
+// Convert decodes a texture and encodes it as target
+func Convert(data []byte, target string) ([]byte, error) {
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(img, target)
+}
+
+// Encode writes img in the format of target
+func Encode(img image.Image, target string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	switch strings.ToLower(target) {
+	case TargetPNG:
+		err := png.Encode(buf, img)
+		if err != nil {
+			return nil, fmt.Errorf("png.Encode: %w", err)
+		}
+	case TargetBMP:
+		err := bmp.Encode(buf, Quantize(img, 256))
+		if err != nil {
+			return nil, fmt.Errorf("bmp.Encode: %w", err)
+		}
+	case TargetDXT1, TargetDXT5:
+		err := encodeDDS(buf, img, strings.ToLower(target) == TargetDXT5)
+		if err != nil {
+			return nil, fmt.Errorf("dds encode: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown target %s", target)
+	}
+	return buf.Bytes(), nil
+}
+
+// Quantize reduces img to a palette of at most count colors using median cut
+func Quantize(img image.Image, count int) *image.Paletted {
+	// bmp has no alpha, so colors are kept as if fully opaque
+	opaque := toNRGBA(img)
+	unique := map[color.NRGBA]bool{}
+	pixels := []color.NRGBA{}
+	for i := 3; i < len(opaque.Pix); i += 4 {
+		opaque.Pix[i] = 255
+		c := color.NRGBA{opaque.Pix[i-3], opaque.Pix[i-2], opaque.Pix[i-1], 255}
+		pixels = append(pixels, c)
+		unique[c] = true
+	}
+
+	palette := color.Palette{}
+	if len(unique) <= count {
+		for c := range unique {
+			palette = append(palette, c)
+		}
+	} else {
+		for _, box := range medianCut(pixels, count) {
+			palette = append(palette, average(box))
+		}
+	}
+	sort.Slice(palette, func(i, j int) bool {
+		a, b := palette[i].(color.NRGBA), palette[j].(color.NRGBA)
+		return uint32(a.R)<<16|uint32(a.G)<<8|uint32(a.B) < uint32(b.R)<<16|uint32(b.G)<<8|uint32(b.B)
+	})
+
+	dst := image.NewPaletted(opaque.Bounds(), palette)
+	draw.Draw(dst, dst.Bounds(), opaque, image.Point{}, draw.Src)
+	return dst
+}
+
+// medianCut splits pixels into count boxes along their widest channel
+func medianCut(pixels []color.NRGBA, count int) [][]color.NRGBA {
+	boxes := [][]color.NRGBA{pixels}
+	for len(boxes) < count {
+		// split the box with the widest channel range
+		best, bestChannel, bestRange := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			channel, spread := widestChannel(box)
+			if spread > bestRange {
+				best, bestChannel, bestRange = i, channel, spread
+			}
+		}
+		if best < 0 {
+			break
+		}
+		box := boxes[best]
+		sort.Slice(box, func(i, j int) bool {
+			return channelValue(box[i], bestChannel) < channelValue(box[j], bestChannel)
+		})
+		half := len(box) / 2
+		boxes[best] = box[:half]
+		boxes = append(boxes, box[half:])
+	}
+	return boxes
+}
+
+func widestChannel(box []color.NRGBA) (int, int) {
+	channel, spread := 0, -1
+	for ch := 0; ch < 3; ch++ {
+		low, high := 255, 0
+		for _, c := range box {
+			v := int(channelValue(c, ch))
+			low = min(low, v)
+			high = max(high, v)
+		}
+		if high-low > spread {
+			channel, spread = ch, high-low
+		}
+	}
+	return channel, spread
+}
+
+func channelValue(c color.NRGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	}
+	return c.B
+}
+
+func average(box []color.NRGBA) color.NRGBA {
+	var r, g, b int
+	for _, c := range box {
+		r += int(c.R)
+		g += int(c.G)
+		b += int(c.B)
+	}
+	n := max(1, len(box))
+	return color.NRGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255}
+}
+
+// encodeDDS writes img as a block compressed dds with a full mip chain
+func encodeDDS(buf *bytes.Buffer, img image.Image, isDXT5 bool) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 || width%4 != 0 || height%4 != 0 {
+		return fmt.Errorf("dimensions %dx%d must be multiples of 4", width, height)
+	}
+
+	mips := []*image.NRGBA{toNRGBA(img)}
+	for w, h := width, height; w > 1 || h > 1; {
+		w, h = max(1, w/2), max(1, h/2)
+		mips = append(mips, halve(mips[len(mips)-1], w, h))
+	}
+
+	blockSize := 8
+	fourCC := "DXT1"
+	if isDXT5 {
+		blockSize = 16
+		fourCC = "DXT5"
+	}
+
+	header := make([]byte, ddsHeaderSize)
+	copy(header, "DDS ")
+	le := binary.LittleEndian
+	le.PutUint32(header[4:], 124)
+	le.PutUint32(header[8:], 0x1|0x2|0x4|0x1000|0x20000|0x80000) // caps, height, width, pixel format, mip count, linear size
+	le.PutUint32(header[12:], uint32(height))
+	le.PutUint32(header[16:], uint32(width))
+	le.PutUint32(header[20:], uint32((width/4)*(height/4)*blockSize))
+	le.PutUint32(header[28:], uint32(len(mips)))
+	le.PutUint32(header[76:], 32)
+	le.PutUint32(header[80:], 0x4) // fourcc
+	copy(header[84:], fourCC)
+	le.PutUint32(header[108:], 0x1000|0x8|0x400000) // texture, complex, mipmap
+	buf.Write(header)
+
+	for _, mip := range mips {
+		encodeBlocks(buf, mip, isDXT5)
+	}
+	return nil
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
+
+// halve box filters src down to width by height
+func halve(src *image.NRGBA, width int, height int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var r, g, b, a, n int
+			for dy := 0; dy < 2; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					sx, sy := min(sw-1, x*2+dx), min(sh-1, y*2+dy)
+					c := src.NRGBAAt(sx, sy)
+					r += int(c.R)
+					g += int(c.G)
+					b += int(c.B)
+					a += int(c.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{uint8(r / n), uint8(g / n), uint8(b / n), uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// encodeBlocks compresses every 4x4 block of img, edge pixels are repeated for mips smaller than a block
+func encodeBlocks(buf *bytes.Buffer, img *image.NRGBA, isDXT5 bool) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	for by := 0; by < max(1, (height+3)/4); by++ {
+		for bx := 0; bx < max(1, (width+3)/4); bx++ {
+			block := [16]color.NRGBA{}
+			for i := range block {
+				x := min(width-1, bx*4+i%4)
+				y := min(height-1, by*4+i/4)
+				block[i] = img.NRGBAAt(x, y)
+			}
+			if isDXT5 {
+				buf.Write(encodeAlphaBlock(block))
+			}
+			buf.Write(encodeColorBlock(block, !isDXT5))
+		}
+	}
+}
+
+// encodeColorBlock range fits the colors of a block between its darkest and brightest
+// pixel. With isPunchThrough, pixels under half alpha use the dxt1 transparent index
+func encodeColorBlock(block [16]color.NRGBA, isPunchThrough bool) []byte {
+	isTransparent := false
+	low, high := [3]int{255, 255, 255}, [3]int{0, 0, 0}
+	for _, c := range block {
+		if isPunchThrough && c.A < 128 {
+			isTransparent = true
+			continue
+		}
+		for ch, v := range [3]uint8{c.R, c.G, c.B} {
+			low[ch] = min(low[ch], int(v))
+			high[ch] = max(high[ch], int(v))
+		}
+	}
+	if low[0] > high[0] {
+		// every pixel is transparent
+		low, high = [3]int{}, [3]int{}
+	}
+
+	c0, c1 := to565(high), to565(low)
+	if isTransparent {
+		// three color mode needs c0 <= c1
+		if c0 > c1 {
+			c0, c1 = c1, c0
+		}
+	} else if c0 < c1 {
+		c0, c1 = c1, c0
+	} else if c0 == c1 {
+		// four color mode needs c0 > c1, an all index 0 block is fine
+		if c1 > 0 {
+			c1--
+		} else {
+			c0++
+		}
+	}
+
+	p0, p1 := from565(c0), from565(c1)
+	palette := [][3]int{p0, p1}
+	if c0 > c1 {
+		palette = append(palette, mix(p0, p1, 2, 1, 3), mix(p0, p1, 1, 2, 3))
+	} else {
+		palette = append(palette, mix(p0, p1, 1, 1, 2))
+	}
+
+	var indices uint32
+	for i, c := range block {
+		index := 0
+		if isTransparent && c.A < 128 {
+			index = 3
+		} else {
+			best := -1
+			for j, p := range palette {
+				d := distance(p, [3]int{int(c.R), int(c.G), int(c.B)})
+				if best < 0 || d < best {
+					best, index = d, j
+				}
+			}
+		}
+		indices |= uint32(index) << (2 * i)
+	}
+
+	out := make([]byte, 8)
+	binary.LittleEndian.PutUint16(out[0:], c0)
+	binary.LittleEndian.PutUint16(out[2:], c1)
+	binary.LittleEndian.PutUint32(out[4:], indices)
+	return out
+}
+
+// encodeAlphaBlock stores the alpha of a block as eight interpolated levels
+func encodeAlphaBlock(block [16]color.NRGBA) []byte {
+	a0, a1 := 0, 255
+	for _, c := range block {
+		a0 = max(a0, int(c.A))
+		a1 = min(a1, int(c.A))
+	}
+	if a0 == a1 {
+		if a1 > 0 {
+			a1--
+		} else {
+			a0++
+		}
+	}
+
+	levels := [8]int{a0, a1}
+	for i := 1; i < 7; i++ {
+		levels[i+1] = ((7-i)*a0 + i*a1) / 7
+	}
+
+	var indices uint64
+	for i, c := range block {
+		index, best := 0, -1
+		for j, level := range levels {
+			d := abs(level - int(c.A))
+			if best < 0 || d < best {
+				best, index = d, j
+			}
+		}
+		indices |= uint64(index) << (3 * i)
+	}
+
+	out := make([]byte, 8)
+	out[0] = uint8(a0)
+	out[1] = uint8(a1)
+	for i := 0; i < 6; i++ {
+		out[2+i] = uint8(indices >> (8 * i))
+	}
+	return out
+}
+
+func to565(c [3]int) uint16 {
+	return uint16(c[0]>>3)<<11 | uint16(c[1]>>2)<<5 | uint16(c[2]>>3)
+}
+
+func from565(v uint16) [3]int {
+	r := int(v>>11) & 0x1f
+	g := int(v>>5) & 0x3f
+	b := int(v) & 0x1f
+	return [3]int{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2}
+}
+
+func mix(a [3]int, b [3]int, wa int, wb int, div int) [3]int {
+	return [3]int{(a[0]*wa + b[0]*wb) / div, (a[1]*wa + b[1]*wb) / div, (a[2]*wa + b[2]*wb) / div}
+}
+
+func distance(a [3]int, b [3]int) int {
+	return (a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1]) + (a[2]-b[2])*(a[2]-b[2])
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
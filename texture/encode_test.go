@@ -0,0 +1,104 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// quadrants returns a size x size image of four solid colors that survive every
+// target, within rounding of 565 block compression
+func quadrants(size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	colors := []color.NRGBA{
+		{255, 0, 0, 255},
+		{0, 255, 0, 255},
+		{0, 0, 255, 255},
+		{255, 255, 255, 255},
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetNRGBA(x, y, colors[(y*2/size)*2+x*2/size])
+		}
+	}
+	return img
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantFormat string
+		wantMips   int
+		tolerance  int // per channel, 565 loses the low bits
+	}{
+		{target: TargetPNG, wantFormat: "png", wantMips: 1},
+		{target: TargetBMP, wantFormat: "bmp", wantMips: 1},
+		{target: TargetDXT1, wantFormat: "dds", wantMips: 5, tolerance: 8},
+		{target: TargetDXT5, wantFormat: "dds", wantMips: 5, tolerance: 8},
+	}
+	src := quadrants(16)
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			data, err := Encode(src, tt.target)
+			if err != nil {
+				t.Fatalf("encode: %s", err)
+			}
+			info, err := Inspect(data)
+			if err != nil {
+				t.Fatalf("inspect: %s", err)
+			}
+			if info.Format != tt.wantFormat || info.Width != 16 || info.Height != 16 || info.MipCount != tt.wantMips {
+				t.Errorf("inspect = %s %dx%d %d mips, want %s 16x16 %d mips", info.Format, info.Width, info.Height, info.MipCount, tt.wantFormat, tt.wantMips)
+			}
+
+			img, err := Decode(data)
+			if err != nil {
+				t.Fatalf("decode: %s", err)
+			}
+			if img.Bounds().Dx() != 16 || img.Bounds().Dy() != 16 {
+				t.Fatalf("decoded %dx%d, want 16x16", img.Bounds().Dx(), img.Bounds().Dy())
+			}
+			for y := 0; y < 16; y++ {
+				for x := 0; x < 16; x++ {
+					got := color.NRGBAModel.Convert(img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y)).(color.NRGBA)
+					want := src.NRGBAAt(x, y)
+					if !isClose(got, want, tt.tolerance) {
+						t.Fatalf("pixel %d,%d = %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeDDSSize(t *testing.T) {
+	_, err := Encode(quadrants(6), TargetDXT1)
+	if err == nil {
+		t.Errorf("encoded a 6x6 dds, want an error for sizes not a multiple of 4")
+	}
+}
+
+func TestInspectDX10(t *testing.T) {
+	data, err := Encode(quadrants(8), TargetDXT1)
+	if err != nil {
+		t.Fatalf("encode: %s", err)
+	}
+	copy(data[84:], "DX10")
+	_, err = Inspect(data)
+	if err == nil {
+		t.Errorf("inspected a DX10 dds, want an error")
+	}
+	_, err = Decode(data)
+	if err == nil {
+		t.Errorf("decoded a DX10 dds, want an error")
+	}
+}
+
+func isClose(a color.NRGBA, b color.NRGBA, tolerance int) bool {
+	for _, d := range []int{int(a.R) - int(b.R), int(a.G) - int(b.G), int(a.B) - int(b.B), int(a.A) - int(b.A)} {
+		if d > tolerance || -d > tolerance {
+			return false
+		}
+	}
+	return true
+}
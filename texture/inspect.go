@@ -0,0 +1,256 @@
+package texture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+const ddsHeaderSize = 128 // magic plus DDS_HEADER
+
+// maxScaledSide caps the width and height Scale produces, 4096x4096 RGBA is 64MB
+const maxScaledSide = 4096
+
+// Info describes how a texture is stored
+type Info struct {
+	Format      string
+	Width       int
+	Height      int
+	MipCount    int // always at least 1
+	Compression string
+}
+
+// Inspect reads the header of a dds, bmp or png texture
+func Inspect(data []byte) (*Info, error) {
+	info := &Info{Format: Format(data), MipCount: 1}
+	switch info.Format {
+	case "dds":
+		if len(data) < ddsHeaderSize {
+			return nil, fmt.Errorf("dds header too short")
+		}
+		if isDX10(data) {
+			return nil, fmt.Errorf("dds with a DX10 header is not supported")
+		}
+		info.Height = int(binary.LittleEndian.Uint32(data[12:]))
+		info.Width = int(binary.LittleEndian.Uint32(data[16:]))
+		mipCount := int(binary.LittleEndian.Uint32(data[28:]))
+		if mipCount > 1 {
+			info.MipCount = mipCount
+		}
+		info.Compression = ddsCompression(data)
+	case "bmp":
+		if len(data) < 34 {
+			return nil, fmt.Errorf("bmp header too short")
+		}
+		info.Width = int(int32(binary.LittleEndian.Uint32(data[18:])))
+		info.Height = int(int32(binary.LittleEndian.Uint32(data[22:])))
+		if info.Height < 0 {
+			info.Height = -info.Height
+		}
+		bitCount := binary.LittleEndian.Uint16(data[28:])
+		switch binary.LittleEndian.Uint32(data[30:]) {
+		case 1:
+			info.Compression = "RLE8"
+		case 2:
+			info.Compression = "RLE4"
+		case 3:
+			info.Compression = "bitfields"
+		default:
+			info.Compression = "none"
+		}
+		if bitCount <= 8 {
+			info.Compression += fmt.Sprintf(", %d-bit paletted", bitCount)
+		} else {
+			info.Compression += fmt.Sprintf(", %d-bit", bitCount)
+		}
+	case "png":
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("png config: %w", err)
+		}
+		info.Width = cfg.Width
+		info.Height = cfg.Height
+		info.Compression = "deflate"
+		if _, ok := cfg.ColorModel.(color.Palette); ok {
+			info.Compression += ", paletted"
+		}
+	default:
+		return nil, fmt.Errorf("unknown texture format")
+	}
+	return info, nil
+}
+
+// ddsCompression names the pixel format of a dds header
+func ddsCompression(data []byte) string {
+	flags := binary.LittleEndian.Uint32(data[80:])
+	if flags&0x4 != 0 {
+		return string(data[84:88])
+	}
+	bitCount := binary.LittleEndian.Uint32(data[88:])
+	if flags&0x1 != 0 {
+		return fmt.Sprintf("uncompressed %d-bit with alpha", bitCount)
+	}
+	return fmt.Sprintf("uncompressed %d-bit", bitCount)
+}
+
+// isDX10 reports if a dds has the DX10 extended header, which moves the
+// pixel data and describes formats the decoder can't read
+func isDX10(data []byte) bool {
+	return len(data) >= ddsHeaderSize && ddsCompression(data) == "DX10"
+}
+
+// ddsBlockSize returns the bytes per 4x4 block of a compressed dds, or 0 for uncompressed
+func ddsBlockSize(data []byte) int {
+	switch ddsCompression(data) {
+	case "DXT1":
+		return 8
+	case "DXT2", "DXT3", "DXT4", "DXT5":
+		return 16
+	}
+	return 0
+}
+
+// DecodeMip returns one mip level of a texture, level 0 being the full image.
+// Only dds textures have more than one level
+func DecodeMip(data []byte, level int) (image.Image, error) {
+	if level == 0 {
+		return Decode(data)
+	}
+	info, err := Inspect(data)
+	if err != nil {
+		return nil, err
+	}
+	if level < 0 || level >= info.MipCount {
+		return nil, fmt.Errorf("mip level %d out of range, texture has %d", level, info.MipCount)
+	}
+
+	blockSize := ddsBlockSize(data)
+	bitCount := int(binary.LittleEndian.Uint32(data[88:]))
+	levelSize := func(width int, height int) int {
+		if blockSize > 0 {
+			return max(1, (width+3)/4) * max(1, (height+3)/4) * blockSize
+		}
+		return width * height * bitCount / 8
+	}
+
+	offset := ddsHeaderSize
+	width, height := info.Width, info.Height
+	for i := 0; i < level; i++ {
+		offset += levelSize(width, height)
+		width = max(1, width/2)
+		height = max(1, height/2)
+	}
+	size := levelSize(width, height)
+	if offset+size > len(data) {
+		return nil, fmt.Errorf("mip level %d is past the end of the texture", level)
+	}
+
+	// rewrite the header to describe only this level so the dds decoder can read it
+	mip := make([]byte, 0, ddsHeaderSize+size)
+	mip = append(mip, data[:ddsHeaderSize]...)
+	// the decoder only reads whole blocks, so small levels are decoded padded and cropped
+	paddedWidth, paddedHeight := width, height
+	if blockSize > 0 {
+		paddedWidth = max(4, (width+3)/4*4)
+		paddedHeight = max(4, (height+3)/4*4)
+	}
+	binary.LittleEndian.PutUint32(mip[12:], uint32(paddedHeight))
+	binary.LittleEndian.PutUint32(mip[16:], uint32(paddedWidth))
+	binary.LittleEndian.PutUint32(mip[28:], 1)
+	pitch := size
+	if blockSize == 0 {
+		pitch = width * bitCount / 8
+	}
+	binary.LittleEndian.PutUint32(mip[20:], uint32(pitch))
+	mip = append(mip, data[offset:offset+size]...)
+
+	img, err := decodeDDS(mip)
+	if err != nil {
+		return nil, fmt.Errorf("mip %d: %w", level, err)
+	}
+	if paddedWidth != width || paddedHeight != height {
+		sub, ok := img.(interface {
+			SubImage(r image.Rectangle) image.Image
+		})
+		if ok {
+			img = sub.SubImage(image.Rect(0, 0, width, height))
+		}
+	}
+	return img, nil
+}
+
+// Channels is a set of color channels to display
+type Channels struct {
+	Red   bool
+	Green bool
+	Blue  bool
+	Alpha bool
+}
+
+// Filter keeps only the chosen channels of img. Alpha alone is shown as
+// grayscale, otherwise alpha is drawn over a checkerboard
+func Filter(img image.Image, channels Channels) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	isAlphaOnly := channels.Alpha && !channels.Red && !channels.Green && !channels.Blue
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			if isAlphaOnly {
+				dst.SetRGBA(x, y, color.RGBA{c.A, c.A, c.A, 255})
+				continue
+			}
+			if !channels.Red {
+				c.R = 0
+			}
+			if !channels.Green {
+				c.G = 0
+			}
+			if !channels.Blue {
+				c.B = 0
+			}
+			if !channels.Alpha {
+				c.A = 255
+			}
+
+			// checkerboard of 8 pixel squares behind transparent pixels
+			bg := uint32(204)
+			if (x/8+y/8)%2 == 0 {
+				bg = 255
+			}
+			a := uint32(c.A)
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((uint32(c.R)*a + bg*(255-a)) / 255),
+				G: uint8((uint32(c.G)*a + bg*(255-a)) / 255),
+				B: uint8((uint32(c.B)*a + bg*(255-a)) / 255),
+				A: 255,
+			})
+		}
+	}
+	return dst
+}
+
+// MaxZoom returns the largest zoom Scale allows for img
+func MaxZoom(img image.Image) float64 {
+	bounds := img.Bounds()
+	return float64(maxScaledSide) / float64(max(1, bounds.Dx(), bounds.Dy()))
+}
+
+// Scale resizes img by zoom using nearest neighbour so pixels stay sharp.
+// Zoom is capped by MaxZoom so large textures don't allocate huge images
+func Scale(img image.Image, zoom float64) image.Image {
+	zoom = min(zoom, max(1, MaxZoom(img)))
+	if zoom == 1 {
+		return img
+	}
+	bounds := img.Bounds()
+	width := max(1, int(float64(bounds.Dx())*zoom))
+	height := max(1, int(float64(bounds.Dy())*zoom))
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.NearestNeighbor.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,93 @@
+package texture
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/malashin/dds"
+	"github.com/sergeymakinen/go-bmp"
+)
+
+// IsTextureExt returns true if ext is a texture extension found in EQ archives
+func IsTextureExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".dds", ".bmp", ".png":
+		return true
+	}
+	return false
+}
+
+// Format sniffs the texture format of data, EQ archives often store dds data in .bmp entries
+func Format(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("DDS ")):
+		return "dds"
+	case bytes.HasPrefix(data, []byte("BM")):
+		return "bmp"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	}
+	return ""
+}
+
+// Decode returns the full resolution image of a dds, bmp or png texture
+func Decode(data []byte) (image.Image, error) {
+	var img image.Image
+	var err error
+	switch Format(data) {
+	case "dds":
+		if isDX10(data) {
+			return nil, fmt.Errorf("dds with a DX10 header is not supported")
+		}
+		img, err = decodeDDS(data)
+		if err != nil {
+			return nil, err
+		}
+	case "bmp":
+		img, err = bmp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("bmp.Decode: %w", err)
+		}
+	case "png":
+		img, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("png.Decode: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown texture format")
+	}
+	return img, nil
+}
+
+// decodeDDS decodes a dds texture, the decoder panics on truncated data so
+// that is reported as an error instead
+func decodeDDS(data []byte) (img image.Image, err error) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			err = fmt.Errorf("dds.Decode: %v", r)
+		}
+	}()
+	img, err = dds.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("dds.Decode: %w", err)
+	}
+	return img, nil
+}
+
+// EncodePNG decodes a texture and writes it as png
+func EncodePNG(w io.Writer, data []byte) error {
+	img, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(w, img)
+	if err != nil {
+		return fmt.Errorf("png.Encode: %w", err)
+	}
+	return nil
+}
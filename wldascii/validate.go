@@ -0,0 +1,175 @@
+// Package wldascii checks the ASCII form of WLD files before they are stored
+package wldascii
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xackery/quail/wld/virtual"
+)
+
+// Problem is a syntax error found on a line, Line is 1 based and 0 when the
+// problem is not tied to a line
+type Problem struct {
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// lineRegex finds the line number in a quail parse error, either "line N" or
+// "file:N:" with the file the line is in
+var lineRegex = regexp.MustCompile(`(?i)line (\d+)|([^\s:"]+):(\d+):`)
+
+// Validate parses text with quail's ASCII WLD parser and returns the problem it
+// reports. name is the file text was read from. Files named by INCLUDE are read
+// with include by their path relative to name, so text parses as it would in
+// place; a nil include leaves them unresolved
+func Validate(text string, name string, include func(name string) ([]byte, error)) []Problem {
+	problem := parse(text, name, include)
+	if problem == nil {
+		return []Problem{}
+	}
+	return []Problem{*problem}
+}
+
+// parse writes text and its includes to a temp dir for quail's ASCII WLD
+// parser, which takes a path, and returns its error with the line number
+// pulled out when it has one
+func parse(text string, name string, include func(name string) ([]byte, error)) *Problem {
+	dir, err := os.MkdirTemp("", "quail-gui-wld")
+	if err != nil {
+		return &Problem{Message: fmt.Sprintf("parse: %s", err)}
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Base(name)
+	if name == "" {
+		base = "validate.wce"
+	}
+	err = os.WriteFile(filepath.Join(dir, base), []byte(text), 0644)
+	if err != nil {
+		return &Problem{Message: fmt.Sprintf("parse: %s", err)}
+	}
+	if include != nil {
+		err = writeIncludes(dir, ".", text, include, map[string]bool{})
+		if err != nil {
+			return &Problem{Message: fmt.Sprintf("include: %s", err)}
+		}
+	}
+
+	err = (&virtual.Wld{}).ReadAscii(filepath.Join(dir, base))
+	if err == nil {
+		return nil
+	}
+	return parseProblem(strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), ""), base)
+}
+
+// writeIncludes copies the files included by text, which is in rel of dir,
+// into dir, following the includes of included files too
+func writeIncludes(dir string, rel string, text string, include func(name string) ([]byte, error), seen map[string]bool) error {
+	for _, line := range strings.Split(text, "\n") {
+		tokens, err := tokenize(line)
+		if err != nil || len(tokens) < 2 || !strings.EqualFold(tokens[0], "INCLUDE") {
+			continue
+		}
+		name := path.Join(rel, filepath.ToSlash(strings.Trim(tokens[1], `"`)))
+		if name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) || filepath.VolumeName(filepath.FromSlash(name)) != "" {
+			return fmt.Errorf("%s is outside the directory of the wld", tokens[1])
+		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		data, err := include(name)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(target, data, 0644)
+		if err != nil {
+			return err
+		}
+		err = writeIncludes(dir, path.Dir(name), string(data), include, seen)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseProblem maps a parse error to the line it names, dropping the line
+// number from the message when it leads it. A line in an included file rather
+// than in base is only kept in the message
+func parseProblem(message string, base string) *Problem {
+	match := lineRegex.FindStringSubmatch(message)
+	if match == nil {
+		return &Problem{Message: fmt.Sprintf("parse: %s", message)}
+	}
+	if match[2] != "" && !strings.EqualFold(match[2], base) {
+		return &Problem{Message: message}
+	}
+	line, _ := strconv.Atoi(match[1] + match[3])
+	if strings.HasPrefix(message, match[0]) {
+		message = strings.TrimLeft(strings.TrimPrefix(message, match[0]), ": ")
+	}
+	return &Problem{Line: line, Message: message}
+}
+
+// tokenize splits a line on whitespace, keeping quoted strings whole and
+// dropping // comments
+func tokenize(line string) ([]string, error) {
+	tokens := []string{}
+	token := strings.Builder{}
+	isQuoted := false
+	quoteStart := 0
+	flush := func() {
+		if token.Len() > 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case isQuoted:
+			token.WriteByte(c)
+			if c == '"' {
+				isQuoted = false
+				flush()
+			}
+		case c == '"':
+			flush()
+			isQuoted = true
+			quoteStart = i + 1
+			token.WriteByte(c)
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			flush()
+			return tokens, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		default:
+			token.WriteByte(c)
+		}
+	}
+	if isQuoted {
+		return nil, fmt.Errorf("unterminated string starting at column %d", quoteStart)
+	}
+	flush()
+	return tokens, nil
+}
@@ -0,0 +1,97 @@
+package wldascii
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseProblem(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Problem
+	}{
+		{name: "line", message: "line 12: unexpected token", want: Problem{Line: 12, Message: "unexpected token"}},
+		{name: "file and line", message: "zone.wce:7: missing END", want: Problem{Line: 7, Message: "missing END"}},
+		{name: "file case", message: "ZONE.WCE:7: missing END", want: Problem{Line: 7, Message: "missing END"}},
+		{name: "included file", message: "r.wce:3: bad tag", want: Problem{Message: "r.wce:3: bad tag"}},
+		{name: "line inside", message: "read tag: line 4", want: Problem{Line: 4, Message: "read tag: line 4"}},
+		{name: "no line", message: "eof", want: Problem{Message: "parse: eof"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseProblem(tt.message, "zone.wce")
+			if *got != tt.want {
+				t.Errorf("parseProblem = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteIncludes(t *testing.T) {
+	files := map[string]string{
+		"a.wce":     "INCLUDE \"sub/b.wce\"\n",
+		"sub/b.wce": "INCLUDE \"c.wce\" // next to b\nINCLUDE \"../a.wce\"\n",
+		"sub/c.wce": "",
+	}
+	tests := []struct {
+		name      string
+		text      string
+		wantErr   bool
+		wantFiles []string
+	}{
+		{
+			name:      "nested",
+			text:      "// zone\nINCLUDE \"a.wce\"\n",
+			wantFiles: []string{"a.wce", "sub/b.wce", "sub/c.wce"},
+		},
+		{
+			name:      "no includes",
+			text:      "SIMPLESPRITEDEF \"INCLUDE\"\n",
+			wantFiles: []string{},
+		},
+		{
+			name:    "missing",
+			text:    "INCLUDE \"z.wce\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "outside",
+			text:    "INCLUDE \"../a.wce\"\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read := []string{}
+			include := func(name string) ([]byte, error) {
+				data, ok := files[name]
+				if !ok {
+					return nil, fmt.Errorf("%s not found", name)
+				}
+				read = append(read, name)
+				return []byte(data), nil
+			}
+			dir := t.TempDir()
+			err := writeIncludes(dir, ".", tt.text, include, map[string]bool{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeIncludes error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(read, tt.wantFiles) {
+				t.Errorf("read %v, want %v", read, tt.wantFiles)
+			}
+			for _, name := range tt.wantFiles {
+				_, err = os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Errorf("%s not written: %s", name, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Package wldfrag decodes raw WLD fragments into readable fields and the
+// fragment references they hold
+package wldfrag
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xackery/quail/raw"
+)
+
+const (
+	maxItems     = 64  // slice elements expanded before the rest are summarised
+	maxValueSize = 200 // characters shown for a scalar slice
+)
+
+// Field is a decoded value of a fragment, nested names are joined with dots
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Ref is a fragment index held by a field
+type Ref struct {
+	Field string
+	Index int
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%s -> %d", r.Field, r.Index)
+}
+
+// Fields returns every exported value of frag in declaration order
+func Fields(frag raw.FragmentReadWriter) []Field {
+	w := &walker{}
+	w.walk("", reflect.ValueOf(frag))
+	return w.fields
+}
+
+// Refs returns the fragment indexes frag points at. Integer fields named like
+// FooRef or FooRefs are references, NameRef is a string table offset and zero
+// or negative values are unset or names
+func Refs(frag raw.FragmentReadWriter) []Ref {
+	w := &walker{}
+	w.walk("", reflect.ValueOf(frag))
+	return w.refs
+}
+
+// TypeName is the fragment name of code, or the code in hex when it is unknown
+func TypeName(code int) string {
+	name := raw.FragName(code)
+	if name == "" {
+		return fmt.Sprintf("0x%02X", code)
+	}
+	return name
+}
+
+// Bytes returns frag re-encoded as quail writes it to a wld, which can differ
+// from the bytes it was read from
+func Bytes(frag raw.FragmentReadWriter) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := frag.Write(buf)
+	if err != nil {
+		return nil, fmt.Errorf("write: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type walker struct {
+	fields []Field
+	refs   []Ref
+}
+
+func (w *walker) walk(name string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		w.add(name, "nil")
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			w.add(name, "nil")
+			return
+		}
+		w.walk(name, v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldName := field.Name
+			if name != "" {
+				fieldName = name + "." + field.Name
+			}
+			w.walk(fieldName, v.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		if isScalar(v.Type().Elem()) {
+			w.addScalars(name, v)
+			return
+		}
+		if v.Len() == 0 {
+			w.add(name, "[]")
+			return
+		}
+		for i := 0; i < v.Len() && i < maxItems; i++ {
+			w.walk(fmt.Sprintf("%s[%d]", name, i), v.Index(i))
+		}
+		if v.Len() > maxItems {
+			w.add(name, fmt.Sprintf("... %d more", v.Len()-maxItems))
+		}
+	default:
+		w.add(name, fmt.Sprint(v.Interface()))
+		if isRefName(name) {
+			w.addRef(name, v)
+		}
+	}
+}
+
+func (w *walker) add(name string, value string) {
+	if name == "" {
+		name = "Value"
+	}
+	w.fields = append(w.fields, Field{Name: name, Value: value})
+}
+
+// addScalars adds a slice of numbers or strings as one field
+func (w *walker) addScalars(name string, v reflect.Value) {
+	values := []string{}
+	for i := 0; i < v.Len(); i++ {
+		values = append(values, fmt.Sprint(v.Index(i).Interface()))
+		if isRefName(name) {
+			w.addRef(fmt.Sprintf("%s[%d]", name, i), v.Index(i))
+		}
+	}
+	value := fmt.Sprintf("[%s]", strings.Join(values, " "))
+	if len(value) > maxValueSize {
+		end := maxValueSize
+		for end > 0 && !utf8.RuneStart(value[end]) {
+			end--
+		}
+		value = fmt.Sprintf("%s... (%d items)", value[:end], v.Len())
+	}
+	w.add(name, value)
+}
+
+func (w *walker) addRef(name string, v reflect.Value) {
+	index := 0
+	switch {
+	case v.CanInt():
+		index = int(v.Int())
+	case v.CanUint():
+		index = int(v.Uint())
+	default:
+		return
+	}
+	if index <= 0 {
+		return
+	}
+	w.refs = append(w.refs, Ref{Field: name, Index: index})
+}
+
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isRefName reports if the last part of a field name is FooRef or FooRefs
+func isRefName(name string) bool {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	if name == "NameRef" {
+		return false
+	}
+	return strings.HasSuffix(name, "Ref") || strings.HasSuffix(name, "Refs")
+}
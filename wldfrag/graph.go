@@ -0,0 +1,157 @@
+package wldfrag
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/xackery/quail/raw"
+)
+
+// Graph indexes the references between the fragments of a wld, both what a
+// fragment depends on and what depends on it
+type Graph struct {
+	codes map[int]int
+	deps  map[int][]Ref
+	users map[int][]int
+}
+
+// NewGraph builds the reference graph of fragments
+func NewGraph(fragments map[int]raw.FragmentReadWriter) *Graph {
+	g := &Graph{
+		codes: map[int]int{},
+		deps:  map[int][]Ref{},
+		users: map[int][]int{},
+	}
+	for idx, frag := range fragments {
+		if frag == nil {
+			continue
+		}
+		g.codes[idx] = frag.FragCode()
+		g.deps[idx] = Refs(frag)
+	}
+	for idx, refs := range g.deps {
+		seen := map[int]bool{}
+		for _, ref := range refs {
+			if seen[ref.Index] {
+				continue
+			}
+			seen[ref.Index] = true
+			g.users[ref.Index] = append(g.users[ref.Index], idx)
+		}
+	}
+	for _, users := range g.users {
+		sort.Ints(users)
+	}
+	return g
+}
+
+// Has reports if idx is a fragment of the graph
+func (g *Graph) Has(idx int) bool {
+	_, ok := g.codes[idx]
+	return ok
+}
+
+// Label is the index and fragment name of idx
+func (g *Graph) Label(idx int) string {
+	code, ok := g.codes[idx]
+	if !ok {
+		return fmt.Sprintf("%d: missing", idx)
+	}
+	return fmt.Sprintf("%d: %s", idx, TypeName(code))
+}
+
+// DependsOn returns the references held by idx, including ones to missing fragments
+func (g *Graph) DependsOn(idx int) []Ref {
+	return g.deps[idx]
+}
+
+// ReferencedBy returns the fragments that reference idx, ascending
+func (g *Graph) ReferencedBy(idx int) []int {
+	return g.users[idx]
+}
+
+// Missing returns the references that point at fragments that don't exist, by fragment
+func (g *Graph) Missing() map[int][]Ref {
+	missing := map[int][]Ref{}
+	for idx, refs := range g.deps {
+		for _, ref := range refs {
+			if !g.Has(ref.Index) {
+				missing[idx] = append(missing[idx], ref)
+			}
+		}
+	}
+	return missing
+}
+
+// Dependencies returns idx and every fragment it depends on directly or
+// through other fragments, ascending
+func (g *Graph) Dependencies(idx int) []int {
+	seen := map[int]bool{idx: true}
+	queue := []int{idx}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, ref := range g.deps[next] {
+			if seen[ref.Index] {
+				continue
+			}
+			seen[ref.Index] = true
+			queue = append(queue, ref.Index)
+		}
+	}
+	out := []int{}
+	for v := range seen {
+		out = append(out, v)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. When roots are given
+// only they and their dependencies are written
+func (g *Graph) WriteDOT(w io.Writer, roots ...int) error {
+	seen := map[int]bool{}
+	if len(roots) == 0 {
+		for idx, refs := range g.deps {
+			seen[idx] = true
+			for _, ref := range refs {
+				seen[ref.Index] = true
+			}
+		}
+	}
+	for _, root := range roots {
+		for _, idx := range g.Dependencies(root) {
+			seen[idx] = true
+		}
+	}
+	nodes := []int{}
+	for idx := range seen {
+		nodes = append(nodes, idx)
+	}
+	sort.Ints(nodes)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph wld {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+	for _, idx := range nodes {
+		attrs := ""
+		if !g.Has(idx) {
+			attrs = ", color=red"
+		}
+		fmt.Fprintf(bw, "\tf%d [label=%s%s];\n", idx, strconv.Quote(g.Label(idx)), attrs)
+	}
+	for _, idx := range nodes {
+		for _, ref := range g.deps[idx] {
+			fmt.Fprintf(bw, "\tf%d -> f%d [label=%s];\n", idx, ref.Index, strconv.Quote(ref.Field))
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	err := bw.Flush()
+	if err != nil {
+		return fmt.Errorf("write dot: %w", err)
+	}
+	return nil
+}
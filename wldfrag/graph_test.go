@@ -0,0 +1,156 @@
+package wldfrag
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xackery/quail/raw"
+)
+
+// testFrag is a fragment holding a single reference and a list of them
+type testFrag struct {
+	NameRef     int32
+	MaterialRef int32
+	SpriteRefs  []uint32
+}
+
+func (f *testFrag) FragCode() int              { return 0x30 }
+func (f *testFrag) Read(r io.ReadSeeker) error { return nil }
+func (f *testFrag) Write(w io.Writer) error    { return nil }
+
+// testFragments is 1 -> 2 -> 3, 4 -> 2 and 4 -> 9, fragment 9 missing
+func testFragments() map[int]raw.FragmentReadWriter {
+	return map[int]raw.FragmentReadWriter{
+		1: &testFrag{NameRef: -5, MaterialRef: 2},
+		2: &testFrag{SpriteRefs: []uint32{3}},
+		3: &testFrag{},
+		4: &testFrag{MaterialRef: 2, SpriteRefs: []uint32{9, 2}},
+	}
+}
+
+func TestGraph(t *testing.T) {
+	g := NewGraph(testFragments())
+	tests := []struct {
+		name         string
+		idx          int
+		wantHas      bool
+		wantDeps     []Ref
+		wantUsers    []int
+		wantAllDeps  []int
+		wantLabelEnd string
+	}{
+		{
+			name:        "root",
+			idx:         1,
+			wantHas:     true,
+			wantDeps:    []Ref{{Field: "MaterialRef", Index: 2}},
+			wantAllDeps: []int{1, 2, 3},
+		},
+		{
+			name:        "shared",
+			idx:         2,
+			wantHas:     true,
+			wantDeps:    []Ref{{Field: "SpriteRefs[0]", Index: 3}},
+			wantUsers:   []int{1, 4},
+			wantAllDeps: []int{2, 3},
+		},
+		{
+			name:        "leaf",
+			idx:         3,
+			wantHas:     true,
+			wantUsers:   []int{2},
+			wantAllDeps: []int{3},
+		},
+		{
+			name:    "missing target",
+			idx:     4,
+			wantHas: true,
+			wantDeps: []Ref{
+				{Field: "MaterialRef", Index: 2},
+				{Field: "SpriteRefs[0]", Index: 9},
+				{Field: "SpriteRefs[1]", Index: 2},
+			},
+			wantAllDeps: []int{2, 3, 4, 9},
+		},
+		{
+			name:         "missing",
+			idx:          9,
+			wantUsers:    []int{4},
+			wantAllDeps:  []int{9},
+			wantLabelEnd: "missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if g.Has(tt.idx) != tt.wantHas {
+				t.Errorf("Has = %v, want %v", g.Has(tt.idx), tt.wantHas)
+			}
+			if got := g.DependsOn(tt.idx); len(got) > 0 || len(tt.wantDeps) > 0 {
+				if !reflect.DeepEqual(got, tt.wantDeps) {
+					t.Errorf("DependsOn = %v, want %v", got, tt.wantDeps)
+				}
+			}
+			if got := g.ReferencedBy(tt.idx); len(got) > 0 || len(tt.wantUsers) > 0 {
+				if !reflect.DeepEqual(got, tt.wantUsers) {
+					t.Errorf("ReferencedBy = %v, want %v", got, tt.wantUsers)
+				}
+			}
+			if got := g.Dependencies(tt.idx); !reflect.DeepEqual(got, tt.wantAllDeps) {
+				t.Errorf("Dependencies = %v, want %v", got, tt.wantAllDeps)
+			}
+			if tt.wantLabelEnd != "" && !strings.HasSuffix(g.Label(tt.idx), tt.wantLabelEnd) {
+				t.Errorf("Label = %s, want suffix %s", g.Label(tt.idx), tt.wantLabelEnd)
+			}
+		})
+	}
+
+	missing := g.Missing()
+	want := map[int][]Ref{4: {{Field: "SpriteRefs[0]", Index: 9}}}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("Missing = %v, want %v", missing, want)
+	}
+}
+
+func TestGraphWriteDOT(t *testing.T) {
+	g := NewGraph(testFragments())
+	tests := []struct {
+		name       string
+		roots      []int
+		wantNodes  []string
+		wantAbsent []string
+	}{
+		{
+			name:      "all",
+			wantNodes: []string{"f1 ", "f2 ", "f3 ", "f4 ", "f9 [label=\"9: missing\", color=red]", "f4 -> f9"},
+		},
+		{
+			name:       "root",
+			roots:      []int{2},
+			wantNodes:  []string{"f2 ", "f3 ", "f2 -> f3"},
+			wantAbsent: []string{"f1 ", "f4 "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := g.WriteDOT(buf, tt.roots...)
+			if err != nil {
+				t.Fatalf("WriteDOT: %s", err)
+			}
+			dot := buf.String()
+			for _, node := range tt.wantNodes {
+				if !strings.Contains(dot, node) {
+					t.Errorf("missing %q in\n%s", node, dot)
+				}
+			}
+			for _, node := range tt.wantAbsent {
+				if strings.Contains(dot, "\t"+node) {
+					t.Errorf("unexpected %q in\n%s", node, dot)
+				}
+			}
+		})
+	}
+}